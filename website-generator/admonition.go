@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// admonitionMarkerRe matches the GitHub-style marker opening a callout
+// blockquote, such as "> [!WARNING]", with an optional title after it on
+// the same line.
+var admonitionMarkerRe = regexp.MustCompile(`^\[!([A-Za-z]+)\][ \t]*([^\n]*)\n?`)
+
+// admonition is a kind of callout: its CSS class suffix, Font Awesome icon
+// and default title.
+type admonition struct {
+	kind  string
+	icon  string
+	title string
+}
+
+// admonitions maps the upper-cased markers to callouts. GitHub's CAUTION
+// is styled as danger.
+var admonitions = map[string]admonition{
+	"NOTE":    {"note", "fa-circle-info", "Note"},
+	"TIP":     {"tip", "fa-lightbulb", "Tip"},
+	"WARNING": {"warning", "fa-triangle-exclamation", "Warning"},
+	"DANGER":  {"danger", "fa-circle-exclamation", "Danger"},
+	"CAUTION": {"danger", "fa-circle-exclamation", "Caution"},
+}
+
+// parseAdmonition reports whether the blockquote node opens with a callout
+// marker and, if so, removes the marker from its text. A title after the
+// marker replaces the default one, so pages in other languages can
+// translate it.
+func parseAdmonition(node *blackfriday.Node) (admonition, bool) {
+	para := node.FirstChild
+	if para == nil || para.Type != blackfriday.Paragraph {
+		return admonition{}, false
+	}
+	text := para.FirstChild
+	if text == nil || text.Type != blackfriday.Text {
+		return admonition{}, false
+	}
+	m := admonitionMarkerRe.FindSubmatch(text.Literal)
+	if m == nil {
+		return admonition{}, false
+	}
+	a, ok := admonitions[strings.ToUpper(string(m[1]))]
+	if !ok {
+		return admonition{}, false
+	}
+	if title := strings.TrimSpace(string(m[2])); title != "" {
+		a.title = title
+	}
+
+	text.Literal = text.Literal[len(m[0]):]
+	if len(text.Literal) == 0 {
+		text.Unlink()
+		// A marker on a paragraph of its own leaves it empty
+		if para.FirstChild == nil {
+			para.Unlink()
+		}
+	}
+	return a, true
+}
+
+// writeAdmonitionStart opens the callout a, closed by a "</div>".
+func writeAdmonitionStart(w io.Writer, a admonition) {
+	fmt.Fprintf(w, "<div class=\"admonition admonition-%s\">\n<p class=\"admonition-title\"><i class=\"fa-solid %s\"></i> %s</p>\n",
+		a.kind, a.icon, html.EscapeString(a.title))
+}
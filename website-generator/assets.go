@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// cdnBase is where the templates load third-party assets from. Asset paths
+// in the templates are relative to it and pin the library versions.
+const cdnBase = "https://cdnjs.cloudflare.com/ajax/libs/"
+
+// vendorDir is the output subdirectory holding local copies of the assets
+// in -offline mode, laid out like the CDN.
+const vendorDir = "vendor"
+
+// offlineAssets lists every CDN file the site needs, including the fonts
+// the Font Awesome and KaTeX stylesheets load relative to themselves.
+var offlineAssets = []string{
+	"font-awesome/6.5.1/css/all.min.css",
+	"font-awesome/6.5.1/webfonts/fa-brands-400.woff2",
+	"font-awesome/6.5.1/webfonts/fa-brands-400.ttf",
+	"font-awesome/6.5.1/webfonts/fa-regular-400.woff2",
+	"font-awesome/6.5.1/webfonts/fa-regular-400.ttf",
+	"font-awesome/6.5.1/webfonts/fa-solid-900.woff2",
+	"font-awesome/6.5.1/webfonts/fa-solid-900.ttf",
+	"font-awesome/6.5.1/webfonts/fa-v4compatibility.woff2",
+	"font-awesome/6.5.1/webfonts/fa-v4compatibility.ttf",
+	"mermaid/10.9.1/mermaid.min.js",
+	"KaTeX/0.16.9/katex.min.css",
+	"KaTeX/0.16.9/katex.min.js",
+	"KaTeX/0.16.9/fonts/KaTeX_AMS-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Caligraphic-Bold.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Caligraphic-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Fraktur-Bold.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Fraktur-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Main-Bold.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Main-BoldItalic.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Main-Italic.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Main-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Math-BoldItalic.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Math-Italic.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_SansSerif-Bold.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_SansSerif-Italic.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_SansSerif-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Script-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Size1-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Size2-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Size3-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Size4-Regular.woff2",
+	"KaTeX/0.16.9/fonts/KaTeX_Typewriter-Regular.woff2",
+}
+
+// assetBase returns the prefix for asset paths in the templates: the CDN,
+// or the local vendor directory relative to the page in -offline mode.
+func assetBase(offline bool, siteRoot string) string {
+	if offline {
+		return siteRoot + vendorDir + "/"
+	}
+	return cdnBase
+}
+
+// downloadAssets copies the CDN assets into the output directory. Files
+// already present are kept, so only the first build needs the network.
+func downloadAssets(outputDir string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, asset := range offlineAssets {
+		target := filepath.Join(outputDir, vendorDir, filepath.FromSlash(asset))
+		if _, err := os.Stat(target); err == nil {
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		if err := downloadAsset(client, cdnBase+asset, target); err != nil {
+			return fmt.Errorf("downloading %s: %w", path.Base(asset), err)
+		}
+		fmt.Printf("✓ Downloaded %s/%s\n", vendorDir, asset)
+	}
+	return nil
+}
+
+func downloadAsset(client *http.Client, url, target string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so an interrupted download isn't
+	// mistaken for a complete one on the next build
+	tmp := target + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, target)
+}
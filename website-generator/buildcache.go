@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// buildCacheFile records, in the output directory, what each exercise page
+// was generated from so -incremental builds can skip unchanged pages.
+const buildCacheFile = ".build-cache.json"
+
+// buildCacheFormat is bumped whenever the generator changes how pages are
+// rendered, invalidating every cache.
+const buildCacheFormat = "1"
+
+// buildCache maps each exercise page, relative to the output directory, to
+// a hash of everything it is rendered from: the exercise itself, the
+// metadata of every exercise listed in its sidebar and the template. A nil
+// buildCache regenerates every page. It is safe for concurrent use.
+type buildCache struct {
+	outputDir string
+	version   string
+
+	mu    sync.Mutex
+	pages map[string]string
+	// langs maps each page to the code of its language
+	langs map[string]string
+	// seen holds the pages of every exercise of the languages in built;
+	// the other pages of those languages belong to exercises that no
+	// longer exist
+	seen    map[string]bool
+	built   map[string]bool
+	skipped int
+}
+
+type buildCacheContents struct {
+	Version string            `json:"version"`
+	Pages   map[string]string `json:"pages"`
+	Langs   map[string]string `json:"langs,omitempty"`
+}
+
+// loadBuildCache reads the cache from the output directory. A missing or
+// unreadable cache, or one written for another template or generator
+// version, starts empty so every page is regenerated.
+func loadBuildCache(outputDir string, opts buildOptions) *buildCache {
+	c := &buildCache{
+		outputDir: outputDir,
+		version:   fmt.Sprintf("%s-%s-minify=%t", buildCacheFormat, opts.Templates.exerciseVersion, opts.Minify),
+		pages:     make(map[string]string),
+		langs:     make(map[string]string),
+		seen:      make(map[string]bool),
+		built:     make(map[string]bool),
+	}
+	path := filepath.Join(outputDir, buildCacheFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "⚠️  Ignoring build cache %s: %v\n", path, err)
+		}
+		return c
+	}
+	var stored buildCacheContents
+	if err := json.Unmarshal(content, &stored); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Ignoring build cache %s: %v\n", path, err)
+		return c
+	}
+	if stored.Version == c.version && stored.Pages != nil {
+		c.pages = stored.Pages
+		if stored.Langs != nil {
+			c.langs = stored.Langs
+		}
+	}
+	return c
+}
+
+// expect records that the build covers lang, whose pages are written to
+// outputDir, and that every exercise it lists still exists. The pages of
+// exercises that then fail to build are kept from the last build.
+func (c *buildCache) expect(outputDir string, lang LangConfig, urls urlPolicy) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.built[lang.Code] = true
+	for _, meta := range lang.Metadata {
+		key, err := filepath.Rel(c.outputDir, filepath.Join(outputDir, filepath.FromSlash(urls.pageFile(meta.Filename))))
+		if err != nil {
+			continue
+		}
+		c.seen[filepath.ToSlash(key)] = true
+	}
+}
+
+// removeBuildCache deletes the cache, for builds that regenerate every page
+// without recording them.
+func removeBuildCache(outputDir string) error {
+	err := os.Remove(filepath.Join(outputDir, buildCacheFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing build cache: %w", err)
+	}
+	return nil
+}
+
+// unchanged records that the page at outputPath is rendered from page and
+// reports whether the file on disk is already up to date.
+func (c *buildCache) unchanged(outputPath string, page ExercisePageData) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	// Other exercises only contribute their metadata; their content
+	// changing leaves this page alone
+	inputs := struct {
+		Exercise Exercise
+		All      []manifestEntry
+	}{page.Exercise, manifestEntries(page.All)}
+	content, err := json.Marshal(inputs)
+	if err != nil {
+		return false, fmt.Errorf("hashing page inputs: %w", err)
+	}
+	hash := contentHash(content)
+	key, err := filepath.Rel(c.outputDir, outputPath)
+	if err != nil {
+		return false, err
+	}
+	key = filepath.ToSlash(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[key] = true
+	c.langs[key] = page.Lang
+	if c.pages[key] == hash {
+		if _, err := os.Stat(outputPath); err == nil {
+			c.skipped++
+			return true, nil
+		}
+	}
+	c.pages[key] = hash
+	return false, nil
+}
+
+// forget drops the page at outputPath after it failed to be written, so
+// the next build regenerates it.
+func (c *buildCache) forget(outputPath string) {
+	if c == nil {
+		return
+	}
+	key, err := filepath.Rel(c.outputDir, outputPath)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pages, filepath.ToSlash(key))
+}
+
+// save removes the pages of exercises that are gone from the languages
+// built and writes the cache. Pages of languages left out of the build are
+// kept.
+func (c *buildCache) save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var stale []string
+	for key := range c.pages {
+		if !c.seen[key] && c.built[c.langs[key]] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	for _, key := range stale {
+		path := filepath.Join(c.outputDir, filepath.FromSlash(key))
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing stale page: %w", err)
+		}
+		// Clean URL pages live in a directory of their own
+		if filepath.Base(path) == "index.html" {
+			os.Remove(filepath.Dir(path))
+		}
+		delete(c.pages, key)
+		delete(c.langs, key)
+		fmt.Printf("🗑  Removed stale %s\n", key)
+	}
+
+	content, err := json.MarshalIndent(buildCacheContents{Version: c.version, Pages: c.pages, Langs: c.langs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding build cache: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.outputDir, buildCacheFile), append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing build cache: %w", err)
+	}
+	return nil
+}
+
+// report prints how many exercise pages were up to date.
+func (c *buildCache) report() {
+	if c == nil {
+		return
+	}
+	fmt.Printf("⏭  Incremental build: %d unchanged exercise pages skipped\n", c.skipped)
+}
+
+// contentHash returns the hex SHA-256 of content.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+var (
+	// collapsibleMarkerRe matches the line opening a collapsible section,
+	// `??? "Deep dive"`, or `???+ "Deep dive"` for one that starts open
+	collapsibleMarkerRe = regexp.MustCompile(`^\?\?\?(\+?)[ \t]+"([^"]*)"[ \t]*\r?\n?$`)
+	// detailsTagRe matches the raw HTML tags of a collapsible section
+	detailsTagRe = regexp.MustCompile(`^</?(?i:details|summary)\b`)
+)
+
+// expandCollapsibles turns each `??? "Title"` marker and the lines indented
+// by four spaces below it into a <details> section titled by its
+// <summary>, with the indented markdown rendered inside. Markers in fenced
+// code blocks are left alone.
+func expandCollapsibles(markdown []byte) []byte {
+	if !strings.Contains(string(markdown), "???") {
+		return markdown
+	}
+	lines := strings.SplitAfter(string(markdown), "\n")
+	var out strings.Builder
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := codeFenceRe.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence):
+				fence = ""
+			}
+		}
+		m := collapsibleMarkerRe.FindStringSubmatch(line)
+		if fence != "" || m == nil {
+			out.WriteString(line)
+			continue
+		}
+
+		// The section runs until the first line that is neither blank nor
+		// indented
+		var body []string
+		for i+1 < len(lines) {
+			next := lines[i+1]
+			if strings.TrimSpace(next) != "" && !strings.HasPrefix(next, "    ") && !strings.HasPrefix(next, "\t") {
+				break
+			}
+			body = append(body, dedentLine(next))
+			i++
+		}
+
+		open := ""
+		if m[1] != "" {
+			open = ` open="open"`
+		}
+		out.WriteString("<details class=\"collapsible\"" + open + ">\n<summary>" + m[2] + "</summary>\n\n")
+		out.WriteString(strings.TrimRight(strings.Join(body, ""), "\n"))
+		out.WriteString("\n\n</details>\n\n")
+	}
+	return []byte(out.String())
+}
+
+// dedentLine removes one level of indentation, four spaces or a tab, from
+// line.
+func dedentLine(line string) string {
+	if strings.HasPrefix(line, "\t") {
+		return line[1:]
+	}
+	return strings.TrimPrefix(line, "    ")
+}
+
+// isDetailsParagraph reports whether the paragraph node opens or closes a
+// collapsible section, starting with a raw <details>, <summary> or closing
+// tag. blackfriday doesn't know these as block tags, so it would wrap them
+// in a <p>.
+func isDetailsParagraph(node *blackfriday.Node) bool {
+	first := node.FirstChild
+	// blackfriday leaves an empty text node before a leading tag
+	for first != nil && first.Type == blackfriday.Text && len(first.Literal) == 0 {
+		first = first.Next
+	}
+	return first != nil && first.Type == blackfriday.HTMLSpan && detailsTagRe.Match(first.Literal)
+}
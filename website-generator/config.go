@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exerciseConfig is an exercises file given with -config. It lists the
+// exercises of each language, keyed by language code, in workshop order:
+//
+//	en:
+//	  - filename: 00-introduction-setup
+//	    title: Introduction and Setup
+//	    emoji: 👋
+//	    description: Get started by cloning the Go source code.
+type exerciseConfig map[string][]exerciseMeta
+
+// loadExerciseConfig reads an exercises file and checks that every
+// exercise it references exists, reporting all missing files at once.
+func loadExerciseConfig(path, exercisesDir string) (exerciseConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var config exerciseConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	codes := make([]string, 0, len(config))
+	for code := range config {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		metas := config[code]
+		if _, ok := languageByCode(code); !ok {
+			return nil, fmt.Errorf("%s: unknown language %q", path, code)
+		}
+		seen := make(map[string]bool)
+		for i, meta := range metas {
+			if meta.Filename == "" {
+				return nil, fmt.Errorf("%s: %s exercise %d has no filename", path, code, i+1)
+			}
+			if seen[meta.Filename] {
+				return nil, fmt.Errorf("%s: %s lists %s twice", path, code, meta.Filename)
+			}
+			seen[meta.Filename] = true
+			if meta.Title == "" {
+				metas[i].Title = humanizeExerciseName(meta.Filename)
+			}
+		}
+	}
+	if missing := config.missingFiles(exercisesDir); len(missing) > 0 {
+		return nil, fmt.Errorf("%s references missing exercise files:\n  %s", path, strings.Join(missing, "\n  "))
+	}
+
+	return config, nil
+}
+
+// missingFiles returns the paths of the listed exercises whose markdown
+// file doesn't exist, in language code order. Unknown languages are
+// skipped.
+func (c exerciseConfig) missingFiles(exercisesDir string) []string {
+	codes := make([]string, 0, len(c))
+	for code := range c {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var missing []string
+	for _, code := range codes {
+		lang, ok := languageByCode(code)
+		if !ok {
+			continue
+		}
+		lang = withSourceDir(exercisesDir, lang)
+		for _, meta := range c[code] {
+			mdPath := filepath.Join(exercisesDir, lang.sourceFile(meta.Filename))
+			if _, err := os.Stat(mdPath); err != nil {
+				missing = append(missing, mdPath)
+			}
+		}
+	}
+	return missing
+}
+
+// languageTagRe matches a plausible BCP 47 language tag: a language
+// subtag with optional extended language, script, region, variant,
+// extension and private use subtags, e.g. "en", "es-419" or "zh-Hant-TW".
+var languageTagRe = regexp.MustCompile(`(?i)^[a-z]{2,3}(?:-[a-z]{3}){0,3}(?:-[a-z]{4})?(?:-(?:[a-z]{2}|[0-9]{3}))?(?:-(?:[a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*(?:-[0-9a-wy-z](?:-[a-z0-9]{2,8})+)*(?:-x(?:-[a-z0-9]{1,8})+)?$`)
+
+// checkLanguageTag warns when the code of lang, which pages carry as
+// their lang attribute, isn't a plausible BCP 47 tag.
+func checkLanguageTag(lang LangConfig, diags *diagnostics) {
+	if !languageTagRe.MatchString(lang.Code) {
+		diags.warnf(categoryBuild, "", "language code %q is not a valid BCP 47 tag, so pages declare the wrong language", lang.Code)
+	}
+}
+
+// selectLanguages returns the languages named in a comma-separated list
+// such as "en,es", in the order given.
+func selectLanguages(codes string) ([]LangConfig, error) {
+	var selected []LangConfig
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		lang, ok := languageByCode(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown language %q in -lang", code)
+		}
+		if !languageBuilt(lang.OutputPrefix, selected) {
+			selected = append(selected, lang)
+		}
+	}
+	return selected, nil
+}
+
+// languageBuilt reports whether the language written to outputPrefix is
+// among langs.
+func languageBuilt(outputPrefix string, langs []LangConfig) bool {
+	for _, lang := range langs {
+		if lang.OutputPrefix == outputPrefix {
+			return true
+		}
+	}
+	return false
+}
+
+func languageByCode(code string) (LangConfig, bool) {
+	for _, lang := range languages {
+		if lang.Code == code {
+			return lang, true
+		}
+	}
+	return LangConfig{}, false
+}
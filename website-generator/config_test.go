@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestLanguageTagRe(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"en", true},
+		{"es", true},
+		{"EN-us", true},
+		{"es-419", true},
+		{"zh-Hant-TW", true},
+		{"sr-Latn-RS", true},
+		{"de-CH-1996", true},
+		{"en-US-x-workshop", true},
+		{"", false},
+		{"e", false},
+		{"english", false},
+		{"en_US", false},
+		{"en-", false},
+		{"en US", false},
+		{"123", false},
+	}
+	for _, tt := range tests {
+		if got := languageTagRe.MatchString(tt.tag); got != tt.want {
+			t.Errorf("languageTagRe.MatchString(%q) = %t, want %t", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Diagnostic severities.
+const (
+	severityWarning = "warning"
+	severityError   = "error"
+)
+
+// Diagnostic categories used in the grouped summary.
+const (
+	categoryLinks = "Link issues"
+	categoryBuild = "Build"
+)
+
+// Diagnostic is a single problem found while building or validating the
+// site.
+type Diagnostic struct {
+	Category string `json:"category"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+func (d Diagnostic) String() string {
+	icon := "⚠️ "
+	if d.Severity == severityError {
+		icon = "❌"
+	}
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	if location == "" {
+		return fmt.Sprintf("%s %s", icon, d.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", icon, location, d.Message)
+}
+
+// diagnostics collects Diagnostics from every check so they can be
+// reported together at the end of the run. It is safe for concurrent use.
+type diagnostics struct {
+	mu    sync.Mutex
+	items []Diagnostic
+}
+
+func (d *diagnostics) add(diag Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = append(d.items, diag)
+}
+
+func (d *diagnostics) warnf(category, file, format string, args ...any) {
+	d.add(Diagnostic{Category: category, File: file, Message: fmt.Sprintf(format, args...), Severity: severityWarning})
+}
+
+func (d *diagnostics) errorf(category, file, format string, args ...any) {
+	d.add(Diagnostic{Category: category, File: file, Message: fmt.Sprintf(format, args...), Severity: severityError})
+}
+
+// errorCount returns the number of error-severity diagnostics.
+func (d *diagnostics) errorCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, diag := range d.items {
+		if diag.Severity == severityError {
+			n++
+		}
+	}
+	return n
+}
+
+// reset discards collected diagnostics before a rebuild.
+func (d *diagnostics) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = nil
+}
+
+// printSummary prints a per-category count line and, when verbose, every
+// diagnostic grouped under its category.
+func (d *diagnostics) printSummary(verbose bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.items) == 0 {
+		return
+	}
+
+	byCategory := make(map[string][]Diagnostic)
+	for _, diag := range d.items {
+		byCategory[diag.Category] = append(byCategory[diag.Category], diag)
+	}
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	counts := make([]string, len(categories))
+	for i, category := range categories {
+		counts[i] = fmt.Sprintf("%s: %d", category, len(byCategory[category]))
+	}
+	fmt.Printf("📋 %s\n", strings.Join(counts, ", "))
+
+	if !verbose {
+		fmt.Println("   (run with -verbose for details)")
+		return
+	}
+	for _, category := range categories {
+		fmt.Printf("\n%s:\n", category)
+		for _, diag := range byCategory[category] {
+			fmt.Printf("  %s\n", diag)
+		}
+	}
+}
+
+// writeJSON writes all diagnostics to path as a JSON array.
+func (d *diagnostics) writeJSON(path string) error {
+	d.mu.Lock()
+	items := append([]Diagnostic{}, d.items...)
+	d.mu.Unlock()
+
+	content, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding diagnostics: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing diagnostics: %w", err)
+	}
+	return nil
+}
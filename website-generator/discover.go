@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// exerciseFileRe matches an exercise name such as "02-scanner-arrow-operator"
+// once the language suffix has been removed.
+var exerciseFileRe = regexp.MustCompile(`^([0-9]+)-[A-Za-z0-9_-]+$`)
+
+// discoverExercises scans the exercises directory and its subdirectories
+// for NN-name files in the given language and returns them sorted by their
+// numeric prefix. Exercises in subdirectories keep their relative path in
+// the name, e.g. "scanner/02-scanner-arrow-operator". Titles and
+// descriptions come from the language's Metadata when an entry exists for
+// the file; otherwise the title is derived from the filename.
+func discoverExercises(exercisesDir string, lang LangConfig) ([]exerciseMeta, error) {
+	overrides := make(map[string]exerciseMeta, len(lang.Metadata))
+	for _, meta := range lang.Metadata {
+		overrides[meta.Filename] = meta
+	}
+
+	type numbered struct {
+		number int
+		meta   exerciseMeta
+	}
+	root := filepath.Join(exercisesDir, lang.SourceDir)
+	var found []numbered
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// Per-language folders belong to their own language
+			if lang.SourceDir == "" && filepath.Dir(p) == root && isLanguageDir(root, entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		suffix := lang.fileSuffixOf(entry.Name())
+		if suffix == "" {
+			return nil
+		}
+		// The name must not contain dots, so "NN-name.es.md" is not picked
+		// up as an English exercise
+		m := exerciseFileRe.FindStringSubmatch(strings.TrimSuffix(entry.Name(), suffix))
+		if m == nil {
+			return nil
+		}
+		number, _ := strconv.Atoi(m[1])
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), suffix)
+		// NN-name.md wins over an NN-name.markdown next to it
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		meta, ok := overrides[name]
+		if !ok {
+			meta = exerciseMeta{Filename: name, Title: humanizeExerciseName(name)}
+		}
+		found = append(found, numbered{number, meta})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading exercises directory: %w", err)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].number != found[j].number {
+			return found[i].number < found[j].number
+		}
+		return found[i].meta.Filename < found[j].meta.Filename
+	})
+
+	metas := make([]exerciseMeta, len(found))
+	for i, f := range found {
+		metas[i] = f.meta
+	}
+	return metas, nil
+}
+
+// checkExerciseFiles reports NN-name files on disk that aren't registered
+// and registered exercises whose file is missing, so a forgotten entry
+// doesn't go unnoticed. registered is the language's exercises config, or
+// its compiled-in metadata when configured is false; files the config
+// doesn't list are never built, while unlisted files are still discovered
+// otherwise, only with a title derived from their name. The problems are
+// warnings, or errors with -strict.
+func checkExerciseFiles(exercisesDir string, lang LangConfig, registered []exerciseMeta, configured bool, opts buildOptions) error {
+	// Without metadata every discovered file is expected to be unlisted
+	if !configured && len(registered) == 0 {
+		return nil
+	}
+	report := opts.diags.warnf
+	if opts.Strict {
+		report = opts.diags.errorf
+	}
+
+	onDisk, err := discoverExercises(exercisesDir, lang)
+	if err != nil {
+		return err
+	}
+	listed := make(map[string]bool, len(registered))
+	for _, meta := range registered {
+		listed[meta.Filename] = true
+	}
+	found := make(map[string]bool, len(onDisk))
+	for _, meta := range onDisk {
+		found[meta.Filename] = true
+		switch {
+		case listed[meta.Filename]:
+		case configured:
+			report(categoryBuild, lang.sourceFile(meta.Filename), "not listed in the exercises config, so no page is generated")
+		default:
+			report(categoryBuild, lang.sourceFile(meta.Filename), "no exercise metadata entry, the title is derived from the filename")
+		}
+	}
+	// The config is checked for missing files when it is loaded
+	if !configured {
+		for _, meta := range registered {
+			if !found[meta.Filename] {
+				report(categoryBuild, lang.sourceFile(meta.Filename), "listed in the exercise metadata but the file does not exist")
+			}
+		}
+	}
+	return nil
+}
+
+// withSourceDir returns lang set up for the layout of the exercises
+// directory. When it has a folder named after the language, e.g. "es/",
+// that folder holds the language's exercises as plain NN-name.md files;
+// otherwise they sit in the exercises directory itself with the language's
+// file suffix.
+func withSourceDir(exercisesDir string, lang LangConfig) LangConfig {
+	lang.exercisesDir = exercisesDir
+	if isLanguageDir(exercisesDir, lang.Code) {
+		lang.SourceDir = lang.Code
+		lang.FileSuffix = ".md"
+	}
+	return lang
+}
+
+// isLanguageDir reports whether name is a language code with a folder of
+// that name inside exercisesDir.
+func isLanguageDir(exercisesDir, name string) bool {
+	if _, ok := languageByCode(name); !ok {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(exercisesDir, name))
+	return err == nil && info.IsDir()
+}
+
+// sourceFile returns the path of an exercise's markdown file relative to
+// the exercises directory, e.g. "02-scanner-arrow-operator.es.md" or
+// "es/02-scanner-arrow-operator.md". It is the .markdown file when only
+// that one exists, e.g. "02-scanner-arrow-operator.es.markdown".
+func (l LangConfig) sourceFile(name string) string {
+	file := filepath.Join(l.SourceDir, filepath.FromSlash(name)+l.FileSuffix)
+	if l.exercisesDir == "" {
+		return file
+	}
+	if _, err := os.Stat(filepath.Join(l.exercisesDir, file)); err == nil {
+		return file
+	}
+	alt := filepath.Join(l.SourceDir, filepath.FromSlash(name)+l.markdownSuffix())
+	if _, err := os.Stat(filepath.Join(l.exercisesDir, alt)); err == nil {
+		return alt
+	}
+	return file
+}
+
+// markdownSuffix returns the language's file suffix with the longer
+// .markdown extension, e.g. ".es.markdown" for ".es.md".
+func (l LangConfig) markdownSuffix() string {
+	return strings.TrimSuffix(l.FileSuffix, ".md") + ".markdown"
+}
+
+// fileSuffixOf returns the language suffix file ends with, either of them,
+// or "" when it isn't one of the language's markdown files.
+func (l LangConfig) fileSuffixOf(file string) string {
+	for _, suffix := range []string{l.FileSuffix, l.markdownSuffix()} {
+		if strings.HasSuffix(file, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// isMarkdownFile reports whether path has a markdown extension, .md or
+// .markdown.
+func isMarkdownFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// humanizeExerciseName turns "07-runtime-patient-go" into
+// "Runtime Patient Go".
+func humanizeExerciseName(name string) string {
+	words := strings.FieldsFunc(exerciseSlug(name), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiscoverExercisesMarkdownExtensions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"01-foo.md",
+		"01-foo.markdown",
+		"02-bar.markdown",
+		"02-bar.es.markdown",
+		"03-baz.es.md",
+		"notes.markdown",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Title\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		lang LangConfig
+		want []string
+	}{
+		{LangConfig{Code: "en", FileSuffix: ".md"}, []string{"01-foo", "02-bar"}},
+		{LangConfig{Code: "es", FileSuffix: ".es.md"}, []string{"02-bar", "03-baz"}},
+	}
+	for _, tt := range tests {
+		metas, err := discoverExercises(dir, tt.lang)
+		if err != nil {
+			t.Fatalf("discoverExercises(%s): %v", tt.lang.Code, err)
+		}
+		var got []string
+		for _, meta := range metas {
+			got = append(got, meta.Filename)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("discoverExercises(%s) = %q, want %q", tt.lang.Code, got, tt.want)
+		}
+	}
+}
+
+func TestSourceFilePrefersMd(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"01-foo.md", "01-foo.markdown", "02-bar.markdown"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# Title\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lang := withSourceDir(dir, LangConfig{Code: "en", FileSuffix: ".md"})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"01-foo", "01-foo.md"},
+		{"02-bar", "02-bar.markdown"},
+	}
+	for _, tt := range tests {
+		if got := lang.sourceFile(tt.name); got != tt.want {
+			t.Errorf("sourceFile(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsMarkdownFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"exercises/01-foo.md", true},
+		{"exercises/01-foo.markdown", true},
+		{"exercises/01-foo.es.markdown", true},
+		{"exercises/images/diagram.png", false},
+		{"exercises/01-foo.md.orig", false},
+	}
+	for _, tt := range tests {
+		if got := isMarkdownFile(tt.path); got != tt.want {
+			t.Errorf("isMarkdownFile(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// editBaseURL returns the URL that "Edit this page" links append an
+// exercise's source file to: the repository's edit view of branch, at the
+// exercises directory. The directory's place in the repository comes from
+// git; when git can't tell, it is assumed to be a top-level directory of
+// the same name.
+func editBaseURL(repoURL, branch, exercisesDir string, diags *diagnostics) string {
+	prefix, err := repoPrefix(exercisesDir)
+	if err != nil {
+		abs, _ := filepath.Abs(exercisesDir)
+		prefix = filepath.Base(abs) + "/"
+		diags.warnf(categoryBuild, "", "%v, assuming the exercises are in %s of the repository", err, prefix)
+	}
+	repo := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	return repo + "/edit/" + url.PathEscape(branch) + "/" + escapePath(prefix)
+}
+
+// repoPrefix returns the path of dir inside its git repository, such as
+// "exercises/", or "" at the root.
+func repoPrefix(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-prefix")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git rev-parse: %s", msg)
+		}
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// editURL links an exercise's markdown source, file relative to the
+// exercises directory, in the repository; it is empty without -repo-url.
+func editURL(base, file string) string {
+	if base == "" {
+		return ""
+	}
+	return base + escapePath(filepath.ToSlash(file))
+}
+
+// escapePath escapes each segment of a slash-separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
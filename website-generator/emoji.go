@@ -0,0 +1,152 @@
+package main
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kyokomi/emoji/v2"
+)
+
+// emojiShortcodeRe matches a :name: shortcode such as :rocket: or :+1:.
+var emojiShortcodeRe = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+
+// emojiCodes maps gemoji shortcodes, colons included, to their emoji.
+var emojiCodes = emoji.CodeMap()
+
+// replaceEmojiShortcodes replaces the known shortcodes in text with their
+// emoji. Unknown ones, like the :30: in a time, are left as they are.
+func replaceEmojiShortcodes(text []byte) []byte {
+	return emojiShortcodeRe.ReplaceAllFunc(text, func(code []byte) []byte {
+		if e, ok := emojiCodes[string(code)]; ok {
+			return []byte(e)
+		}
+		return code
+	})
+}
+
+// keywordEmoji picks an exercise's emoji when neither its metadata nor
+// its front matter gives one, by the first keyword found in its filename
+// or title. The order matters: gofmt exercises also mention the AST.
+var keywordEmoji = []struct {
+	keyword string
+	emoji   string
+}{
+	{"introduction", "🚀"},
+	{"setup", "🚀"},
+	{"gofmt", "✨"},
+	{"scanner", "⚡"},
+	{"parser", "🌳"},
+	{"ast", "🌳"},
+	{"inlining", "📦"},
+	{"ssa", "🔬"},
+	{"goroutine", "🧵"},
+	{"select", "🎲"},
+	{"stack", "📚"},
+	{"work-stealing", "🤝"},
+	{"runtime", "⚙️"},
+	{"compiler", "🛠️"},
+	{"compile", "🛠️"},
+}
+
+// defaultExerciseEmoji is the emoji of exercises no keyword matches.
+const defaultExerciseEmoji = "📄"
+
+// pickEmoji returns the emoji keywordEmoji gives an exercise, matching
+// the words of its filename first, since they are the same in every
+// language, and then of its title.
+func pickEmoji(filename, title string) string {
+	name := strings.ToLower(exerciseSlug(path.Base(filename)))
+	title = strings.ToLower(title)
+	for _, text := range []string{name, title} {
+		for _, k := range keywordEmoji {
+			if containsWord(text, k.keyword) {
+				return k.emoji
+			}
+		}
+	}
+	return defaultExerciseEmoji
+}
+
+// containsWord reports whether word appears in text between word
+// boundaries, so "ast" doesn't match "fast".
+func containsWord(text, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// isSingleEmoji reports whether s is exactly one emoji as readers see it:
+// one grapheme cluster, which may take several runes. It accepts an emoji
+// with its variation selector, skin tone modifier or keycap mark, tag
+// sequences such as subdivision flags, flags made of two regional
+// indicators, and any of these joined into one by zero-width joiners, as
+// in 👩‍💻.
+func isSingleEmoji(s string) bool {
+	runes := []rune(s)
+	if len(runes) == 2 && isRegionalIndicator(runes[0]) && isRegionalIndicator(runes[1]) {
+		return true
+	}
+	for i := 0; ; i++ {
+		if i >= len(runes) {
+			return false
+		}
+		base := runes[i]
+		keycap := false
+		for i+1 < len(runes) && isEmojiModifier(runes[i+1]) {
+			i++
+			keycap = keycap || runes[i] == 0x20E3
+		}
+		// Digits, # and * are only emoji as keycaps
+		if !isEmojiBase(base) && !(keycap && strings.ContainsRune("0123456789#*", base)) {
+			return false
+		}
+		if i+1 == len(runes) {
+			return true
+		}
+		if runes[i+1] != 0x200D {
+			return false
+		}
+		i++
+	}
+}
+
+// isEmojiBase reports whether r starts an emoji: the pictographs,
+// dingbats and symbols blocks, plus the few scattered symbols with an
+// emoji presentation.
+func isEmojiBase(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, r >= 0x2600 && r <= 0x27BF,
+		r >= 0x2300 && r <= 0x23FF, r >= 0x2B00 && r <= 0x2BFF,
+		r >= 0x2190 && r <= 0x21FF, r >= 0x25A0 && r <= 0x25FF:
+		return true
+	}
+	switch r {
+	case 0x00A9, 0x00AE, 0x203C, 0x2049, 0x2122, 0x2139, 0x24C2, 0x2934, 0x2935, 0x3030, 0x303D, 0x3297, 0x3299:
+		return true
+	}
+	return false
+}
+
+// isEmojiModifier reports whether r changes the emoji before it rather
+// than starting another: a variation selector, a skin tone, the keycap
+// mark or a tag character.
+func isEmojiModifier(r rune) bool {
+	return r == 0xFE0E || r == 0xFE0F || (r >= 0x1F3FB && r <= 0x1F3FF) || r == 0x20E3 || (r >= 0xE0020 && r <= 0xE007F)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// epubChapter is one exercise in the EPUB.
+type epubChapter struct {
+	ID      string
+	File    string
+	Number  int
+	Title   string
+	Content string
+}
+
+// epubResource is an image the chapters show or link to, such as a
+// screenshot, stored at the same path relative to the output root as on
+// the site.
+type epubResource struct {
+	ID        string
+	Href      string
+	MediaType string
+	content   []byte
+}
+
+// xhtmlVoidRe matches HTML void elements, which XHTML needs self-closed.
+var xhtmlVoidRe = regexp.MustCompile(`<(area|br|col|embed|hr|img|input|link|meta|source|wbr)\b([^<>]*?)\s*/?>`)
+
+// htmlEntityRe matches named character references. XHTML only knows the
+// five XML ones; blackfriday's typographic quotes and dashes are spelled
+// with others.
+var htmlEntityRe = regexp.MustCompile(`&[a-zA-Z][a-zA-Z0-9]*;`)
+
+// generateEPUB writes the exercises of a language to file as an EPUB 3
+// book, with a toc.ncx for older readers: one XHTML chapter per exercise,
+// from the same rendered markdown as all.html, and the images they show,
+// read from the generated site in outputDir. Links between exercises point
+// at their chapters. Chapters that aren't well-formed XHTML, which readers
+// refuse to open, are reported as build errors.
+func generateEPUB(file, outputDir string, lang LangConfig, exercises []Exercise, opts buildOptions) error {
+	chapterFiles := make(map[string]string, len(exercises))
+	for _, ex := range exercises {
+		chapterFiles[ex.Name] = strings.ReplaceAll(ex.Name, "/", "-") + ".xhtml"
+	}
+
+	ui := lang.UIStrings
+	var resources []epubResource
+	seen := make(map[string]bool)
+	chapters := make([]epubChapter, len(exercises))
+	modified := time.Time{}
+	for i, ex := range exercises {
+		content, _ := anchorHeadings(epubLinks(ex.pageHTML, ex.Name, chapterFiles))
+		content = assetRefRe.ReplaceAllStringFunc(content, func(match string) string {
+			m := assetRefRe.FindStringSubmatch(match)
+			ref := m[2]
+			rel := path.Join(lang.OutputPrefix, ref)
+			mediaType := mime.TypeByExtension(path.Ext(rel))
+			if !isAssetRef(m[1], ref) || strings.HasPrefix(rel, "../") || !strings.HasPrefix(mediaType, "image/") {
+				return match
+			}
+			if !seen[rel] {
+				// Missing files were reported when the site was generated
+				data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(rel)))
+				if err != nil {
+					return match
+				}
+				seen[rel] = true
+				resources = append(resources, epubResource{
+					ID:        "res-" + strconv.Itoa(len(resources)),
+					Href:      rel,
+					MediaType: mediaType,
+					content:   data,
+				})
+			}
+			return m[1] + html.EscapeString(rel) + m[3] + `"`
+		})
+		chapters[i] = epubChapter{
+			ID:      "ch-" + strconv.Itoa(i),
+			File:    chapterFiles[ex.Name],
+			Number:  ex.Number,
+			Title:   ex.Title,
+			Content: toXHTML(content),
+		}
+		if ex.LastUpdated.After(modified) {
+			modified = ex.LastUpdated
+		}
+	}
+	modified = modified.UTC().Truncate(time.Second)
+
+	// The identifier only changes with the exercise list, so readers keep
+	// their place across rebuilds
+	var names strings.Builder
+	names.WriteString(lang.Code)
+	for _, ex := range exercises {
+		names.WriteString("\n" + ex.Name)
+	}
+	id := contentHash([]byte(names.String()))
+
+	data := struct {
+		Lang        string
+		Identifier  string
+		Title       string
+		Description string
+		Authors     []string
+		Modified    string
+		Exercise    string
+		Contents    string
+		Chapters    []epubChapter
+		Resources   []epubResource
+	}{
+		Lang:        lang.Code,
+		Identifier:  fmt.Sprintf("urn:uuid:%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32]),
+		Title:       ui.HeroTitle,
+		Description: ui.HeroLead,
+		Authors:     exerciseAuthors(exercises),
+		Modified:    modified.Format("2006-01-02T15:04:05Z"),
+		Exercise:    ui.Exercise,
+		Contents:    ui.Contents,
+		Chapters:    chapters,
+		Resources:   resources,
+	}
+
+	// Documents in the book, in the order they are stored after mimetype
+	type epubFile struct {
+		name    string
+		content []byte
+	}
+	var files []epubFile
+	render := func(name, tmpl string, data any) error {
+		t, err := template.New(name).Funcs(template.FuncMap{"add": func(a, b int) int { return a + b }}).Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("parsing %s template: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return fmt.Errorf("executing %s template: %w", name, err)
+		}
+		files = append(files, epubFile{name, buf.Bytes()})
+		return nil
+	}
+	if err := render("META-INF/container.xml", epubContainerTemplate, nil); err != nil {
+		return err
+	}
+	if err := render("OEBPS/content.opf", epubPackageTemplate, data); err != nil {
+		return err
+	}
+	if err := render("OEBPS/toc.ncx", epubNCXTemplate, data); err != nil {
+		return err
+	}
+	if err := render("OEBPS/nav.xhtml", epubNavTemplate, data); err != nil {
+		return err
+	}
+	for i, ex := range exercises {
+		chapter := struct {
+			epubChapter
+			Lang     string
+			Exercise string
+		}{chapters[i], lang.Code, ui.Exercise}
+		if err := render("OEBPS/"+chapters[i].File, epubChapterTemplate, chapter); err != nil {
+			return err
+		}
+		if err := checkXHTML(files[len(files)-1].content); err != nil {
+			opts.diags.errorf(categoryBuild, lang.sourceFile(ex.Name), "EPUB chapter is not well-formed XHTML: %v", err)
+		}
+	}
+	files = append(files, epubFile{"OEBPS/epub.css", []byte(epubCSS)})
+	for _, res := range resources {
+		files = append(files, epubFile{"OEBPS/" + res.Href, res.content})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	// Readers identify the book by an uncompressed mimetype entry stored
+	// first
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("writing EPUB: %w", err)
+	}
+	io.WriteString(w, "application/epub+zip")
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return fmt.Errorf("writing EPUB: %w", err)
+		}
+		if _, err := w.Write(f.content); err != nil {
+			return fmt.Errorf("writing EPUB: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("writing EPUB: %w", err)
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing EPUB: %w", err)
+	}
+
+	fmt.Printf("✓ Generated %s (%d chapters) [%s]\n", file, len(chapters), lang.Code)
+	return nil
+}
+
+// epubLinks points links to other exercises at their chapter file, and
+// links to the index at the table of contents. from is the exercise the
+// HTML belongs to.
+func epubLinks(rendered, from string, chapterFiles map[string]string) string {
+	return renderedPageLinkRe.ReplaceAllStringFunc(rendered, func(match string) string {
+		m := renderedPageLinkRe.FindStringSubmatch(match)
+		target := resolvePageLink(from, m[1], m[2])
+		if target == "index" {
+			return `href="nav.xhtml"`
+		}
+		if file, ok := chapterFiles[target]; ok {
+			return fmt.Sprintf(`href="%s%s"`, file, m[3])
+		}
+		return match
+	})
+}
+
+// toXHTML makes rendered HTML well-formed XHTML: void elements are closed
+// and named character references become numeric ones.
+func toXHTML(rendered string) string {
+	rendered = xhtmlVoidRe.ReplaceAllString(rendered, "<$1$2 />")
+	return htmlEntityRe.ReplaceAllStringFunc(rendered, func(entity string) string {
+		switch entity {
+		case "&amp;", "&lt;", "&gt;", "&quot;", "&apos;":
+			return entity
+		}
+		text := html.UnescapeString(entity)
+		if text == entity {
+			return entity
+		}
+		var b strings.Builder
+		for _, r := range text {
+			fmt.Fprintf(&b, "&#%d;", r)
+		}
+		return b.String()
+	})
+}
+
+// checkXHTML reports the first reason content isn't well-formed XML.
+func checkXHTML(content []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+const epubContainerTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+    <rootfiles>
+        <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+    </rootfiles>
+</container>
+`
+
+const epubPackageTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{.Lang}}">
+    <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+        <dc:identifier id="book-id">{{.Identifier}}</dc:identifier>
+        <dc:title>{{html .Title}}</dc:title>
+        <dc:language>{{.Lang}}</dc:language>
+        <dc:description>{{html .Description}}</dc:description>
+        {{range .Authors}}<dc:creator>{{html .}}</dc:creator>
+        {{end}}<meta property="dcterms:modified">{{.Modified}}</meta>
+    </metadata>
+    <manifest>
+        <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+        <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+        <item id="css" href="epub.css" media-type="text/css"/>
+        {{range .Chapters}}<item id="{{.ID}}" href="{{html .File}}" media-type="application/xhtml+xml"/>
+        {{end}}{{range .Resources}}<item id="{{.ID}}" href="{{html .Href}}" media-type="{{.MediaType}}"/>
+        {{end}}
+    </manifest>
+    <spine toc="ncx">
+        <itemref idref="nav"/>
+        {{range .Chapters}}<itemref idref="{{.ID}}"/>
+        {{end}}
+    </spine>
+</package>
+`
+
+const epubNCXTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1" xml:lang="{{.Lang}}">
+    <head>
+        <meta name="dtb:uid" content="{{.Identifier}}"/>
+        <meta name="dtb:depth" content="1"/>
+        <meta name="dtb:totalPageCount" content="0"/>
+        <meta name="dtb:maxPageNumber" content="0"/>
+    </head>
+    <docTitle><text>{{html .Title}}</text></docTitle>
+    <navMap>
+        {{range $i, $ch := .Chapters}}<navPoint id="nav-{{$ch.ID}}" playOrder="{{add $i 1}}">
+            <navLabel><text>{{$ch.Number}}. {{html $ch.Title}}</text></navLabel>
+            <content src="{{html $ch.File}}"/>
+        </navPoint>
+        {{end}}
+    </navMap>
+</ncx>
+`
+
+const epubNavTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Lang}}" xml:lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8"/>
+    <title>{{html .Title}}</title>
+    <link rel="stylesheet" type="text/css" href="epub.css"/>
+</head>
+<body>
+    <h1>{{html .Title}}</h1>
+    <p>{{html .Description}}</p>
+    <nav epub:type="toc" id="toc">
+        <h2>{{html .Contents}}</h2>
+        <ol>
+            {{range .Chapters}}<li><a href="{{html .File}}">{{.Number}}. {{html .Title}}</a></li>
+            {{end}}
+        </ol>
+    </nav>
+</body>
+</html>
+`
+
+const epubChapterTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Lang}}" xml:lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8"/>
+    <title>{{html .Title}}</title>
+    <link rel="stylesheet" type="text/css" href="epub.css"/>
+</head>
+<body>
+    <section epub:type="chapter" id="{{.ID}}">
+        <p class="exercise-number">{{html .Exercise}} {{.Number}}</p>
+        {{.Content}}
+    </section>
+</body>
+</html>
+`
+
+// epubCSS styles the book. The site's stylesheet is made for screens, with
+// dark code blocks and navigation chrome, so e-readers get a plain one
+// that keeps code monospaced and wraps long lines instead of cutting them.
+const epubCSS = `body { margin: 0 4%; line-height: 1.5; }
+h1, h2, h3, h4 { line-height: 1.25; page-break-after: avoid; }
+img { max-width: 100%; }
+.exercise-number { margin: 0; color: #6c757d; font-size: 0.85em; text-transform: uppercase; }
+code, pre, .code-filename { font-family: "DejaVu Sans Mono", Menlo, Consolas, "Courier New", monospace; }
+code { font-size: 0.9em; }
+pre { margin: 1em 0; padding: 0.6em; border: 1px solid #999; font-size: 0.8em; line-height: 1.35; white-space: pre-wrap; word-wrap: break-word; page-break-inside: avoid; }
+.code-filename { margin-top: 1em; padding: 0.2em 0.6em; border: 1px solid #999; border-bottom: none; font-size: 0.8em; }
+.code-filename + pre { margin-top: 0; }
+.chroma .ln { margin-right: 0.8em; color: #999; }
+.chroma .highlight-line { background: #fff3c4; }
+.chroma .k, .chroma .kc, .chroma .kd, .chroma .kn, .chroma .kr, .chroma .kt { font-weight: bold; }
+.chroma .c, .chroma .c1, .chroma .cm { font-style: italic; color: #666; }
+.admonition { margin: 1em 0; padding: 0.5em 1em; border-left: 4px solid #999; }
+.admonition-title { margin: 0; font-weight: bold; }
+li.task-list-item { list-style: none; }
+.playground-run, .heading-anchor { display: none; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 0.6em; border: 1px solid #999; }
+`
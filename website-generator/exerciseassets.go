@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// assetRefRe matches src and href attributes in rendered exercise HTML,
+// with any query or fragment in the third group.
+var assetRefRe = regexp.MustCompile(`(\s(?:src|href)=")([^"#?]*)([#?][^"]*)?"`)
+
+// exerciseAssets copies the local files exercises reference, such as
+// screenshots, into the output directory. Each file keeps its path relative
+// to the exercises directory and is copied, and each missing file
+// reported, once per build. A nil exerciseAssets leaves references
+// untouched.
+type exerciseAssets struct {
+	exercisesDir string
+	outputDir    string
+	diags        *diagnostics
+
+	// mu guards copied and missing, as pages are rendered in parallel
+	mu      sync.Mutex
+	copied  map[string]bool
+	missing map[string]bool
+}
+
+func newExerciseAssets(exercisesDir, outputDir string, diags *diagnostics) *exerciseAssets {
+	return &exerciseAssets{
+		exercisesDir: exercisesDir,
+		outputDir:    outputDir,
+		diags:        diags,
+		copied:       make(map[string]bool),
+		missing:      make(map[string]bool),
+	}
+}
+
+// rewrite copies the files referenced from the rendered HTML of the
+// exercise in mdFile (relative to the exercises directory) and points the
+// references at the copies. siteRoot is the relative path from the page to
+// the output root. Links to pages and to anything outside the exercises
+// directory are left alone, and missing files are reported as warnings.
+func (a *exerciseAssets) rewrite(html, mdFile, siteRoot string) (string, error) {
+	if a == nil {
+		return html, nil
+	}
+	var firstErr error
+	out := assetRefRe.ReplaceAllStringFunc(html, func(match string) string {
+		m := assetRefRe.FindStringSubmatch(match)
+		ref := m[2]
+		if !isAssetRef(m[1], ref) {
+			return match
+		}
+		rel := path.Join(path.Dir(filepath.ToSlash(mdFile)), ref)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return match
+		}
+		src := filepath.Join(a.exercisesDir, filepath.FromSlash(rel))
+		info, err := os.Stat(src)
+		if err != nil || info.IsDir() {
+			a.reportMissing(mdFile, ref)
+			return match
+		}
+		if err := a.copy(rel, src); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
+		return m[1] + siteRoot + rel + m[3] + `"`
+	})
+	return out, firstErr
+}
+
+// isAssetRef reports whether ref, from the given attribute, points at a
+// local file rather than a page, an anchor or another site.
+func isAssetRef(attr, ref string) bool {
+	if ref == "" || strings.HasPrefix(ref, "/") || linkSchemeRe.MatchString(ref) {
+		return false
+	}
+	// Images are always files; links only when they aren't pages
+	if strings.Contains(attr, "src=") {
+		return true
+	}
+	switch path.Ext(ref) {
+	case "", ".html", ".md", ".markdown":
+		return false
+	}
+	return true
+}
+
+func (a *exerciseAssets) reportMissing(mdFile, ref string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if key := mdFile + "\x00" + ref; !a.missing[key] {
+		a.missing[key] = true
+		a.diags.warnf(categoryLinks, mdFile, "referenced file %s not found", ref)
+	}
+}
+
+// copy copies src to rel in the output directory unless this build already
+// did.
+func (a *exerciseAssets) copy(rel, src string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.copied[rel] {
+		return nil
+	}
+
+	dst := filepath.Join(a.outputDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("creating asset directory: %w", err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("copying %s: %w", rel, err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("copying %s: %w", rel, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copying %s: %w", rel, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("copying %s: %w", rel, err)
+	}
+
+	a.copied[rel] = true
+	fmt.Printf("✓ Copied %s\n", rel)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultFavicon is the icon pages use without -favicon. It is inline, so
+// browsers don't fall back to requesting a missing /favicon.ico.
+const defaultFavicon = `data:image/svg+xml,<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 32 32"><rect width="32" height="32" rx="6" fill="%2300ADD8"/><text x="16" y="22" font-family="sans-serif" font-size="15" font-weight="bold" text-anchor="middle" fill="white">Go</text></svg>`
+
+// faviconExts are the icon formats -favicon accepts.
+var faviconExts = map[string]bool{".ico": true, ".png": true, ".svg": true, ".gif": true}
+
+// faviconName is the name of the -favicon copy at the output root, keeping
+// the file's extension so it is served with the right type.
+func faviconName(file string) string {
+	return "favicon" + strings.ToLower(filepath.Ext(file))
+}
+
+// faviconURL is the icon link of a page whose path to the output root is
+// siteRoot.
+func faviconURL(siteRoot string, opts buildOptions) template.URL {
+	if opts.Favicon == "" {
+		return defaultFavicon
+	}
+	return template.URL(siteRoot + faviconName(opts.Favicon))
+}
+
+// copyFavicon copies the -favicon file to the output root, shared by all
+// languages like the stylesheet.
+func copyFavicon(outputDir string, opts buildOptions) error {
+	if opts.Favicon == "" {
+		return nil
+	}
+	content, err := os.ReadFile(opts.Favicon)
+	if err != nil {
+		return fmt.Errorf("reading favicon: %w", err)
+	}
+	name := faviconName(opts.Favicon)
+	if err := writeOutput(filepath.Join(outputDir, name), content, opts.DryRun); err != nil {
+		return fmt.Errorf("writing favicon: %w", err)
+	}
+
+	if !opts.DryRun {
+		fmt.Printf("✓ Copied %s\n", name)
+	}
+	return nil
+}
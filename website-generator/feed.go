@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Lang    string      `xml:"xml:lang,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Link    atomLink `xml:"link"`
+	Updated string   `xml:"updated"`
+	Summary string   `xml:"summary"`
+}
+
+// generateFeed writes feed.xml next to a language's index page, with one
+// Atom entry per exercise, newest first by markdown modification time.
+// Entry ids are built from the exercise slug rather than the page URL, so
+// they stay the same across rebuilds and -trailing-slash changes.
+func generateFeed(outputDir, baseURL string, lang LangConfig, exercises []Exercise, urls urlPolicy) error {
+	sorted := append([]Exercise{}, exercises...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].LastUpdated.Equal(sorted[j].LastUpdated) {
+			return sorted[i].LastUpdated.After(sorted[j].LastUpdated)
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	id := absoluteURL(baseURL, lang, "./")
+	home := absoluteURL(baseURL, lang, urls.pageURL("index"))
+	feed := atomFeed{
+		Xmlns: "http://www.w3.org/2005/Atom",
+		Lang:  lang.Code,
+		ID:    id,
+		Title: lang.UIStrings.HeroTitle,
+		Links: []atomLink{
+			{Href: absoluteURL(baseURL, lang, "feed.xml"), Rel: "self"},
+			{Href: home},
+		},
+		Author: atomAuthor{Name: "Jesús Espino"},
+	}
+	if len(sorted) > 0 {
+		feed.Updated = atomTime(sorted[0].LastUpdated)
+	}
+	for _, ex := range sorted {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      id + "#" + ex.Slug,
+			Title:   fmt.Sprintf("%s %d: %s", lang.UIStrings.Exercise, ex.Number, ex.Title),
+			Link:    atomLink{Href: absoluteURL(baseURL, lang, ex.URL)},
+			Updated: atomTime(ex.LastUpdated),
+			Summary: ex.Description,
+		})
+	}
+
+	content, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding feed: %w", err)
+	}
+	content = append([]byte(xml.Header), content...)
+	if err := os.WriteFile(filepath.Join(outputDir, "feed.xml"), append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing feed: %w", err)
+	}
+
+	fmt.Printf("✓ Generated feed.xml [%s]\n", lang.Code)
+	return nil
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
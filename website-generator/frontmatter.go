@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatter is the optional YAML block at the top of an exercise,
+// delimited by "---" lines. Title, Emoji and Description take precedence
+// over the compiled-in exercise metadata.
+type frontmatter struct {
+	Title       string   `yaml:"title"`
+	Emoji       string   `yaml:"emoji"`
+	Description string   `yaml:"description"`
+	Difficulty  string   `yaml:"difficulty"`
+	Tags        []string `yaml:"tags"`
+	Objectives  []string `yaml:"objectives"`
+	Takeaways   []string `yaml:"takeaways"`
+	// Authors are credited at the bottom of the page
+	Authors []string `yaml:"authors"`
+	// EstimatedMinutes is the hands-on time the author expects the
+	// exercise to take, unlike the reading time, which is computed
+	EstimatedMinutes int `yaml:"estimated_minutes"`
+	// Prerequisites name the exercises to do first, the same way
+	Prerequisites []string `yaml:"prerequisites"`
+	// Related names other exercises by filename without extension, e.g.
+	// "04-compiler-inlining-parameters"
+	Related []string `yaml:"related"`
+}
+
+var frontmatterFence = []byte("---")
+
+// difficulties are the allowed values of the difficulty key.
+var difficulties = []string{"beginner", "intermediate", "advanced"}
+
+// validate checks values that YAML decoding alone can't.
+func (fm frontmatter) validate() error {
+	if fm.Difficulty != "" && !slices.Contains(difficulties, fm.Difficulty) {
+		return fmt.Errorf("front matter: invalid difficulty %q (want %s)", fm.Difficulty, strings.Join(difficulties, ", "))
+	}
+	for _, author := range fm.Authors {
+		if strings.TrimSpace(author) == "" {
+			return errors.New("front matter: empty author")
+		}
+	}
+	if fm.EstimatedMinutes < 0 {
+		return fmt.Errorf("front matter: invalid estimated_minutes %d (want a positive number of minutes)", fm.EstimatedMinutes)
+	}
+	// Tags end up in data attributes and filter state, so keep them to
+	// single words
+	for _, tag := range fm.Tags {
+		if tag == "" || strings.ContainsFunc(tag, unicode.IsSpace) {
+			return fmt.Errorf("front matter: invalid tag %q (tags can't be empty or contain spaces)", tag)
+		}
+	}
+	return nil
+}
+
+// parseFrontmatter splits the front matter from the markdown body and
+// decodes it. Files without front matter return a zero frontmatter and
+// the content unchanged.
+func parseFrontmatter(content []byte) (frontmatter, []byte, error) {
+	var fm frontmatter
+
+	first, rest, _ := bytes.Cut(content, []byte("\n"))
+	if !bytes.Equal(bytes.TrimRight(first, " \t\r"), frontmatterFence) {
+		return fm, content, nil
+	}
+
+	// Find the closing fence on a line of its own
+	var block []byte
+	found := false
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		if bytes.Equal(bytes.TrimRight(line, " \t\r"), frontmatterFence) {
+			found = true
+			break
+		}
+		block = append(block, line...)
+		block = append(block, '\n')
+	}
+	if !found {
+		return fm, nil, errors.New("front matter: missing closing ---")
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(block))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fm); err != nil && !errors.Is(err, io.EOF) {
+		return fm, nil, fmt.Errorf("front matter: %w", err)
+	}
+
+	return fm, rest, nil
+}
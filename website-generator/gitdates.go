@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// loadGitDates returns the time of the last commit touching each file in
+// dir, keyed by its slash-separated path relative to dir. Files git doesn't
+// track are missing from the map.
+func loadGitDates(dir string) (map[string]time.Time, error) {
+	// Each commit is a NUL-prefixed date line followed by the files it
+	// changed, newest commit first
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "log", "--format=%x00%cI", "--name-only", "--relative", "--no-renames", "--", ".")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git log: %s", msg)
+		}
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	dates := make(map[string]time.Time)
+	var current time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			current, err = time.Parse(time.RFC3339, line[1:])
+			if err != nil {
+				return nil, fmt.Errorf("git log: %w", err)
+			}
+		case line != "":
+			if _, ok := dates[line]; !ok {
+				dates[line] = current
+			}
+		}
+	}
+	return dates, scanner.Err()
+}
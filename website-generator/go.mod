@@ -2,4 +2,18 @@ module github.com/jespino/having-fun-with-the-go-source-code-workshop/website-ge
 
 go 1.21
 
-require github.com/russross/blackfriday/v2 v2.1.0
+require (
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/kyokomi/emoji/v2 v2.2.13
+	github.com/russross/blackfriday/v2 v2.1.0
+	github.com/tdewolff/minify/v2 v2.20.37
+	golang.org/x/sync v0.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/dlclark/regexp2 v1.11.0 // indirect
+	github.com/tdewolff/parse/v2 v2.7.15 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+)
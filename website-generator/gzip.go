@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// gzipExts are the kinds of generated files -gzip precompresses.
+var gzipExts = map[string]bool{".html": true, ".css": true, ".json": true}
+
+// gzipStats totals what gzipOutput compressed, for the end of run summary.
+// A nil gzipStats reports nothing.
+type gzipStats struct {
+	files  int
+	before int64
+	after  int64
+}
+
+// gzipOutput writes a best-compression .gz next to every HTML, CSS and JSON
+// file in outputDir, for static hosts that serve precompressed files. It
+// runs once everything else is written, and each .gz takes the
+// modification time of its source so hosts and caches see them as the
+// same version.
+func gzipOutput(outputDir string) (*gzipStats, error) {
+	stats := &gzipStats{}
+	err := filepath.WalkDir(outputDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !gzipExts[filepath.Ext(p)] || entry.Name() == buildCacheFile {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+		zw.ModTime = info.ModTime()
+		if _, err := zw.Write(content); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p+".gz", buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		if err := os.Chtimes(p+".gz", info.ModTime(), info.ModTime()); err != nil {
+			return err
+		}
+
+		stats.files++
+		stats.before += int64(len(content))
+		stats.after += int64(buf.Len())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gzipping output: %w", err)
+	}
+	return stats, nil
+}
+
+func (s *gzipStats) report() {
+	if s == nil || s.before == 0 {
+		return
+	}
+	fmt.Printf("🗜️  Gzipped %d files from %d KB to %d KB, a %.1f:1 compression ratio\n",
+		s.files, s.before/1024, s.after/1024, float64(s.before)/float64(s.after))
+}
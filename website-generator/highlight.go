@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/russross/blackfriday/v2"
+)
+
+// codeFormatter emits CSS classes rather than inline colors; the matching
+// rules for codeStyle live in templates/style.css. numberedCodeFormatter
+// also puts each line's number in a <span class="ln">.
+var (
+	codeFormatter         = chromahtml.New(chromahtml.WithClasses(true))
+	numberedCodeFormatter = chromahtml.New(chromahtml.WithClasses(true), chromahtml.WithLineNumbers(true))
+	codeStyle             = styles.Get("onedark")
+)
+
+// renderCodeBlock writes a fenced code block highlighted by Chroma, with
+// line numbers if lineNumbers is set and the lines its info string picks
+// out emphasized. It reports false, writing nothing, when the block's
+// language can't be highlighted.
+func renderCodeBlock(w io.Writer, node *blackfriday.Node, lineNumbers bool) bool {
+	highlighted, ok := highlightCode(codeBlockLang(node), string(node.Literal), lineNumbers, codeBlockLines(node))
+	if ok && codeBlockLang(node) == "diff" {
+		highlighted = markDiffLines(highlighted, string(node.Literal))
+	}
+	if ok {
+		io.WriteString(w, highlighted)
+	}
+	return ok
+}
+
+// codeLineStart opens every line of Chroma's output, whatever classes
+// follow.
+const codeLineStart = `<span class="line`
+
+// markDiffLines adds a class to each line of a highlighted diff by its
+// marker: diff-add for "+", diff-remove for "-" and diff-hunk for "@@"
+// hunk headers and the "+++"/"---" file headers, so whole lines get a
+// background rather than just their text a color. code is the diff the
+// block was highlighted from; Chroma emits one line span per line of it.
+func markDiffLines(highlighted, code string) string {
+	lines := strings.SplitAfter(code, "\n")
+	var b strings.Builder
+	for i := 0; ; i++ {
+		j := strings.Index(highlighted, codeLineStart)
+		if j < 0 {
+			break
+		}
+		b.WriteString(highlighted[:j+len(codeLineStart)])
+		highlighted = highlighted[j+len(codeLineStart):]
+		if i < len(lines) {
+			if class := diffLineClass(lines[i]); class != "" {
+				b.WriteString(" " + class)
+			}
+		}
+	}
+	b.WriteString(highlighted)
+	return b.String()
+}
+
+// diffLineClass returns the class of a line of a diff, or "" for context
+// lines.
+func diffLineClass(line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		return "diff-hunk"
+	case strings.HasPrefix(line, "+"):
+		return "diff-add"
+	case strings.HasPrefix(line, "-"):
+		return "diff-remove"
+	}
+	return ""
+}
+
+// codeBlockLang returns the language a fenced code block is tagged with,
+// or "" for none.
+func codeBlockLang(node *blackfriday.Node) string {
+	if fields := strings.Fields(string(node.Info)); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// codeBlockFlag reports whether a fenced code block's info string has the
+// bare word flag after the language, as in ```bash substitute.
+func codeBlockFlag(node *blackfriday.Node, flag string) bool {
+	fields := strings.Fields(string(node.Info))
+	return len(fields) > 1 && slices.Contains(fields[1:], flag)
+}
+
+// codeTitleRe matches a title="path/to/file.go" attribute in a fenced code
+// block's info string; the quotes are optional for titles without spaces.
+var codeTitleRe = regexp.MustCompile(`(?:^|\s)title=(?:"([^"]*)"|(\S+))`)
+
+// codeBlockTitle returns the caption a fenced code block's info string
+// gives it, or "" for none.
+func codeBlockTitle(node *blackfriday.Node) string {
+	m := codeTitleRe.FindStringSubmatch(string(node.Info))
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(m[1] + m[2])
+}
+
+// codeLinesRe matches the {2,4-6} set of lines to emphasize in a fenced
+// code block's info string.
+var codeLinesRe = regexp.MustCompile(`(?:^|\s)\{([0-9]+(?:-[0-9]+)?(?:,[0-9]+(?:-[0-9]+)?)*)\}`)
+
+// codeBlockLines returns the ranges of 1-based lines a fenced code block's
+// info string emphasizes, as in ```go {2,4-6}, or nil for none.
+func codeBlockLines(node *blackfriday.Node) [][2]int {
+	m := codeLinesRe.FindStringSubmatch(string(node.Info))
+	if m == nil {
+		return nil
+	}
+	var ranges [][2]int
+	for _, part := range strings.Split(m[1], ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		start, _ := strconv.Atoi(from)
+		end := start
+		if isRange {
+			end, _ = strconv.Atoi(to)
+		}
+		if start > 0 && end >= start {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	return ranges
+}
+
+// highlightCode returns code highlighted as lang, wrapped in <pre
+// class="chroma"><code>, with the lines in the highlight ranges wrapped in
+// <span class="line highlight-line">. It reports false for an empty or
+// unknown language, in which case the caller renders a plain code block.
+func highlightCode(lang, code string, lineNumbers bool, highlight [][2]int) (string, bool) {
+	if lang == "" {
+		return "", false
+	}
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		return "", false
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", false
+	}
+	formatter := codeFormatter
+	if lineNumbers {
+		formatter = numberedCodeFormatter
+	}
+	if len(highlight) > 0 {
+		formatter = chromahtml.New(chromahtml.WithClasses(true), chromahtml.WithLineNumbers(lineNumbers), chromahtml.HighlightLines(highlight))
+	}
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, codeStyle, iterator); err != nil {
+		return "", false
+	}
+	// Chroma marks the lines with its terse "hl" class
+	return strings.ReplaceAll(buf.String(), `<span class="line hl">`, `<span class="line highlight-line">`) + "\n", true
+}
+
+// codeBlock returns code as a highlighted block, or a plain <pre><code>
+// block when the language is unknown.
+func codeBlock(lang, code string) string {
+	if highlighted, ok := highlightCode(lang, code, false, nil); ok {
+		return highlighted
+	}
+	return "<pre><code>" + html.EscapeString(code) + "</code></pre>\n"
+}
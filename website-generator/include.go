@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// includeDirectiveRe matches an {% include "shared/build.md" %} directive
+// on a line of its own.
+var includeDirectiveRe = regexp.MustCompile(`(?m)^\{%[ \t]*include[ \t]+"([^"]+)"[ \t]*%\}[ \t]*\r?$`)
+
+// maxIncludeDepth caps how deeply included files may include others, so a
+// runaway chain fails instead of growing the page without bound.
+const maxIncludeDepth = 10
+
+// expandIncludes replaces every include directive in the markdown of file
+// with the markdown of the file it names, relative to the exercises
+// directory, itself with its includes expanded. An include cycle or a chain
+// deeper than maxIncludeDepth is an error.
+func expandIncludes(markdown []byte, exercisesDir, file string) ([]byte, error) {
+	return expandIncludesFrom(markdown, exercisesDir, []string{filepath.ToSlash(file)})
+}
+
+// expandIncludesFrom expands the includes of the last file of chain, the
+// files being included from the exercise page down.
+func expandIncludesFrom(markdown []byte, exercisesDir string, chain []string) ([]byte, error) {
+	var firstErr error
+	out := includeDirectiveRe.ReplaceAllFunc(markdown, func(match []byte) []byte {
+		if firstErr != nil {
+			return match
+		}
+		name := filepath.ToSlash(filepath.Clean(string(includeDirectiveRe.FindSubmatch(match)[1])))
+		if slices.Contains(chain, name) {
+			firstErr = fmt.Errorf("include cycle: %s → %s", strings.Join(chain, " → "), name)
+			return match
+		}
+		if len(chain) > maxIncludeDepth {
+			firstErr = fmt.Errorf("includes nested deeper than %d: %s → %s", maxIncludeDepth, strings.Join(chain, " → "), name)
+			return match
+		}
+		included, err := os.ReadFile(filepath.Join(exercisesDir, filepath.FromSlash(name)))
+		if err != nil {
+			firstErr = fmt.Errorf("include: %w", err)
+			return match
+		}
+		expanded, err := expandIncludesFrom(included, exercisesDir, append(slices.Clip(chain), name))
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		return bytes.TrimRight(expanded, "\n")
+	})
+	return out, firstErr
+}
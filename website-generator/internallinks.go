@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	hrefAttrRe = regexp.MustCompile(`\shref="([^"]*)"`)
+	idAttrRe   = regexp.MustCompile(`\sid="([^"]*)"`)
+	// scriptRe matches inline scripts, whose markup strings aren't links
+	scriptRe = regexp.MustCompile(`(?s)<script[\s>].*?</script>`)
+	// linkSchemeRe matches hrefs that leave the site, e.g. https: or mailto:
+	linkSchemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// pageLinks holds the hrefs and element ids found in one generated page.
+type pageLinks struct {
+	hrefs []string
+	ids   map[string]bool
+}
+
+// checkInternalLinks verifies that every local link in the generated HTML
+// points at a file that exists and, when it has a fragment, at an element
+// id on the target page. Broken links are recorded as errors. Links under
+// baseURL, which -base-url makes of internal ones, are checked as paths
+// from the output root. It only reads the output directory.
+func checkInternalLinks(outputDir, baseURL string, diags *diagnostics) error {
+	pages := make(map[string]*pageLinks)
+	err := filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".html" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		markup := scriptRe.ReplaceAllString(string(content), "")
+		page := &pageLinks{ids: make(map[string]bool)}
+		for _, m := range hrefAttrRe.FindAllStringSubmatch(markup, -1) {
+			page.hrefs = append(page.hrefs, m[1])
+		}
+		for _, m := range idAttrRe.FindAllStringSubmatch(markup, -1) {
+			page.ids[m[1]] = true
+		}
+		pages[path] = page
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("collecting internal links: %w", err)
+	}
+
+	paths := make([]string, 0, len(pages))
+	for path := range pages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var checked, broken int
+	for _, path := range paths {
+		rel, _ := filepath.Rel(outputDir, path)
+		for _, href := range pages[path].hrefs {
+			if site := siteURLPrefix(baseURL); site != "" && strings.HasPrefix(href, site) {
+				href = "/" + strings.TrimPrefix(href, site)
+			}
+			if href == "" || strings.HasPrefix(href, "//") || linkSchemeRe.MatchString(href) {
+				continue
+			}
+			checked++
+			if problem := checkLocalLink(outputDir, path, href, pages); problem != "" {
+				broken++
+				diags.errorf(categoryLinks, rel, "broken link %s: %s", href, problem)
+			}
+		}
+	}
+
+	fmt.Printf("🔗 Internal links: %d checked, %d broken\n", checked, broken)
+	return nil
+}
+
+// siteURLPrefix returns baseURL ending in a slash, or "" without one.
+func siteURLPrefix(baseURL string) string {
+	if baseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/"
+}
+
+// checkLocalLink resolves href as the browser would from the page at path
+// and returns what is wrong with it, or "" if it is fine. Links starting
+// with "/" are taken relative to the output root, and directory links and
+// extensionless clean URLs resolve like a static host would serve them.
+func checkLocalLink(outputDir, path, href string, pages map[string]*pageLinks) string {
+	target, fragment, _ := strings.Cut(href, "#")
+	target, _, _ = strings.Cut(target, "?")
+
+	var file string
+	switch {
+	case target == "":
+		file = path
+	case strings.HasPrefix(target, "/"):
+		file = filepath.Join(outputDir, filepath.FromSlash(target))
+	default:
+		file = filepath.Join(filepath.Dir(path), filepath.FromSlash(target))
+	}
+
+	info, err := os.Stat(file)
+	switch {
+	case err == nil && info.IsDir():
+		file = filepath.Join(file, "index.html")
+	case err != nil && filepath.Ext(file) == "":
+		file += ".html"
+	}
+	if _, err := os.Stat(file); err != nil {
+		return "target does not exist"
+	}
+
+	if fragment == "" {
+		return ""
+	}
+	page, ok := pages[file]
+	if !ok {
+		return ""
+	}
+	if !page.ids[fragment] {
+		return fmt.Sprintf("no element with id %q", fragment)
+	}
+	return ""
+}
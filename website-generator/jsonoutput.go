@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output formats of -output-format. JSON output carries the same rendered
+// exercises as the HTML pages, for other front-ends to present.
+const (
+	outputFormatHTML = "html"
+	outputFormatJSON = "json"
+)
+
+// jsonExercise is an exercise's JSON file. Content is the exercise HTML as
+// on its page; its links to other exercises use their page URLs, which
+// front-ends can map to their own routes through each exercise's URL.
+type jsonExercise struct {
+	Number      int               `json:"number"`
+	Name        string            `json:"name"`
+	Title       string            `json:"title"`
+	Emoji       string            `json:"emoji,omitempty"`
+	Description string            `json:"description"`
+	URL         string            `json:"url"`
+	Content     string            `json:"content"`
+	Prev        *jsonExerciseLink `json:"prev,omitempty"`
+	Next        *jsonExerciseLink `json:"next,omitempty"`
+}
+
+// jsonExerciseLink points at another exercise: URL is its page URL and
+// File its JSON file, both relative to the language directory.
+type jsonExerciseLink struct {
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	File   string `json:"file"`
+}
+
+// jsonIndex is index.json, listing the exercises of a language in order.
+type jsonIndex struct {
+	Lang        string          `json:"lang"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Exercises   []jsonIndexItem `json:"exercises"`
+}
+
+type jsonIndexItem struct {
+	jsonExerciseLink
+	Emoji       string `json:"emoji,omitempty"`
+	Description string `json:"description"`
+}
+
+// jsonFile returns the output path of an exercise's JSON file, relative to
+// its language directory: where the URL policy puts its page, with a .json
+// extension, so relative links in the content resolve the same way.
+func jsonFile(name string, urls urlPolicy) string {
+	return strings.TrimSuffix(urls.pageFile(name), ".html") + ".json"
+}
+
+// generateJSONOutput writes a JSON file per exercise of a language and
+// index.json, in place of their pages.
+func generateJSONOutput(outputDir string, lang LangConfig, exercises []Exercise, opts buildOptions) error {
+	links := make([]jsonExerciseLink, len(exercises))
+	for i, ex := range exercises {
+		links[i] = jsonExerciseLink{
+			Number: ex.Number,
+			Name:   ex.Name,
+			Title:  ex.Title,
+			URL:    ex.URL,
+			File:   jsonFile(ex.Name, opts.URLs),
+		}
+	}
+
+	index := jsonIndex{
+		Lang:        lang.Code,
+		Title:       lang.UIStrings.HeroTitle,
+		Description: lang.UIStrings.HeroLead,
+		Exercises:   make([]jsonIndexItem, len(exercises)),
+	}
+	for i, ex := range exercises {
+		index.Exercises[i] = jsonIndexItem{jsonExerciseLink: links[i], Emoji: ex.Emoji, Description: ex.Description}
+
+		data := jsonExercise{
+			Number:      ex.Number,
+			Name:        ex.Name,
+			Title:       ex.Title,
+			Emoji:       ex.Emoji,
+			Description: ex.Description,
+			URL:         ex.URL,
+			Content:     string(ex.Content),
+		}
+		if i > 0 {
+			data.Prev = &links[i-1]
+		}
+		if i < len(exercises)-1 {
+			data.Next = &links[i+1]
+		}
+		if err := writeJSONFile(filepath.Join(outputDir, filepath.FromSlash(links[i].File)), data, opts.DryRun); err != nil {
+			return fmt.Errorf("writing %s (%s): %w", links[i].File, lang.Code, err)
+		}
+		if !opts.DryRun {
+			fmt.Printf("✓ Generated %s [%s]\n", links[i].File, lang.Code)
+		}
+	}
+
+	if err := writeJSONFile(filepath.Join(outputDir, "index.json"), index, opts.DryRun); err != nil {
+		return fmt.Errorf("writing index.json (%s): %w", lang.Code, err)
+	}
+	if !opts.DryRun {
+		fmt.Printf("✓ Generated index.json [%s]\n", lang.Code)
+	}
+	return nil
+}
+
+// writeJSONFile writes v to path as indented JSON, creating its directory.
+func writeJSONFile(path string, v any, dryRun bool) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+	}
+	return writeOutput(path, buf.Bytes(), dryRun)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var externalLinkRe = regexp.MustCompile(`(?:href|src)="(https?://[^"#]+)[^"]*"`)
+
+// externalCheckOptions configures the external link checker.
+type externalCheckOptions struct {
+	Concurrency int
+	Timeout     time.Duration
+	CacheFile   string
+	CacheTTL    time.Duration
+	// SiteURL is -base-url; links under it lead to the site's own pages,
+	// which -check-links checks instead.
+	SiteURL string
+}
+
+// linkCacheEntry is a remembered check result stored in the cache file.
+type linkCacheEntry struct {
+	Status    int       `json:"status"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// linkResult is the outcome of checking a single URL.
+type linkResult struct {
+	URL     string
+	Status  int
+	Err     error
+	Cached  bool
+	Sources []string
+}
+
+// soft reports whether a failure is likely transient (rate limiting,
+// server errors, network trouble) and should only warn.
+func (r linkResult) soft() bool {
+	return r.Err != nil || r.Status == http.StatusTooManyRequests || r.Status >= 500
+}
+
+func (r linkResult) ok() bool {
+	return r.Err == nil && r.Status < 400
+}
+
+// checkExternalLinks checks every external link found in the generated HTML
+// and prints a summary. Definitely broken links (e.g. 404) are recorded as
+// errors; rate limiting and server errors only produce warnings.
+func checkExternalLinks(outputDir string, opts externalCheckOptions, diags *diagnostics) error {
+	sources, err := collectExternalLinks(outputDir, opts.SiteURL)
+	if err != nil {
+		return err
+	}
+
+	cache := loadLinkCache(opts.CacheFile)
+	client := &http.Client{Timeout: opts.Timeout}
+
+	urls := make([]string, 0, len(sources))
+	for u := range sources {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	results := make([]linkResult, len(urls))
+	sem := make(chan struct{}, max(opts.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		if entry, ok := cache[u]; ok && time.Since(entry.CheckedAt) < opts.CacheTTL {
+			results[i] = linkResult{URL: u, Status: entry.Status, Cached: true, Sources: sources[u]}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			status, err := fetchStatus(client, u)
+			results[i] = linkResult{URL: u, Status: status, Err: err, Sources: sources[u]}
+		}(i, u)
+	}
+	wg.Wait()
+
+	var checked, cached, warnings, failed int
+	for _, r := range results {
+		if r.Cached {
+			cached++
+		} else {
+			checked++
+			// Transient failures are not cached so they get retried
+			if !r.soft() {
+				cache[r.URL] = linkCacheEntry{Status: r.Status, CheckedAt: time.Now().UTC()}
+			}
+		}
+
+		switch {
+		case r.ok():
+		case r.soft():
+			warnings++
+			diags.warnf(categoryLinks, r.Sources[0], "%s: %s%s", r.URL, describeLinkResult(r), morePages(r.Sources))
+		default:
+			failed++
+			diags.errorf(categoryLinks, r.Sources[0], "%s: %s%s", r.URL, describeLinkResult(r), morePages(r.Sources))
+		}
+	}
+
+	if err := saveLinkCache(opts.CacheFile, cache); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔗 External links: %d checked, %d cached, %d warnings, %d failed\n", checked, cached, warnings, failed)
+	return nil
+}
+
+// morePages notes how many other pages share a link, so a link in the
+// footer is reported once rather than once per page.
+func morePages(sources []string) string {
+	if len(sources) < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" (and %d more pages)", len(sources)-1)
+}
+
+// collectExternalLinks maps each external URL in the generated HTML to the
+// pages that reference it, leaving out those under siteURL.
+func collectExternalLinks(outputDir, siteURL string) (map[string][]string, error) {
+	site := siteURLPrefix(siteURL)
+	sources := make(map[string][]string)
+	err := filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".html" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(outputDir, path)
+		seen := make(map[string]bool)
+		for _, m := range externalLinkRe.FindAllStringSubmatch(string(content), -1) {
+			if u := m[1]; !seen[u] && (site == "" || !strings.HasPrefix(u, site)) {
+				seen[u] = true
+				sources[u] = append(sources[u], rel)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collecting external links: %w", err)
+	}
+	return sources, nil
+}
+
+// fetchStatus issues a HEAD request, falling back to GET for servers that
+// don't support HEAD properly.
+func fetchStatus(client *http.Client, url string) (int, error) {
+	status, err := doRequest(client, http.MethodHead, url)
+	if err == nil && status != http.StatusMethodNotAllowed && status != http.StatusForbidden && status != http.StatusNotImplemented {
+		return status, nil
+	}
+	return doRequest(client, http.MethodGet, url)
+}
+
+func doRequest(client *http.Client, method, url string) (int, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "go-workshop-link-checker")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func describeLinkResult(r linkResult) string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return fmt.Sprintf("HTTP %d", r.Status)
+}
+
+// loadLinkCache reads the cache file. A missing or unreadable cache simply
+// means every link gets checked again.
+func loadLinkCache(path string) map[string]linkCacheEntry {
+	cache := make(map[string]linkCacheEntry)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "⚠️  Ignoring link cache %s: %v\n", path, err)
+		}
+		return cache
+	}
+	if err := json.Unmarshal(content, &cache); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Ignoring link cache %s: %v\n", path, err)
+		return make(map[string]linkCacheEntry)
+	}
+	return cache
+}
+
+func saveLinkCache(path string, cache map[string]linkCacheEntry) error {
+	content, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding link cache: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing link cache: %w", err)
+	}
+	return nil
+}
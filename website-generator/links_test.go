@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestFixRelativeLink(t *testing.T) {
+	tests := []struct {
+		dest string
+		want string
+	}{
+		{"02-foo.md", "02-foo.html"},
+		{"02-foo.md#section", "02-foo.html#section"},
+		{"02-foo.md?x=1", "02-foo.html?x=1"},
+		{"02-foo.md?x=1#section", "02-foo.html?x=1#section"},
+		{"./02-foo.md", "02-foo.html"},
+		{"02-foo.es.md#section", "02-foo.html#section"},
+		{"runtime/07-patient-go.md#step-1", "runtime/07-patient-go.html#step-1"},
+		{"../exercises/02-foo.md#bar", "02-foo.html#bar"},
+		{"../README.md#setup", "index.html#setup"},
+		{"../../README.md", "index.html"},
+		{"02-foo.MD", "02-foo.html"},
+		{"02-foo.Md#section", "02-foo.html#section"},
+		{"../README.MD#setup", "index.html#setup"},
+		{"02-foo.markdown", "02-foo.html"},
+		{"02-foo.markdown#section", "02-foo.html#section"},
+		{"02-foo.es.markdown?x=1", "02-foo.html?x=1"},
+		{"../exercises/02-foo.markdown#bar", "02-foo.html#bar"},
+		{"02-foo.MARKDOWN", "02-foo.html"},
+		{"https://go.dev/doc/install.md", "https://go.dev/doc/install.md"},
+		{"https://github.com/golang/go#readme", "https://github.com/golang/go#readme"},
+		{"mailto:someone@example.com", "mailto:someone@example.com"},
+		{"#section", "#section"},
+		{"notes.md", "notes.md"},
+		{"02-foo.html#section", "02-foo.html#section"},
+	}
+	for _, tt := range tests {
+		if got := fixRelativeLink(tt.dest); got != tt.want {
+			t.Errorf("fixRelativeLink(%q) = %q, want %q", tt.dest, got, tt.want)
+		}
+	}
+}
+
+func TestFixRelativeLinkExtensionsAgree(t *testing.T) {
+	for _, name := range []string{"02-foo", "runtime/07-patient-go", "../exercises/02-foo"} {
+		md, markdown := fixRelativeLink(name+".md"), fixRelativeLink(name+".markdown")
+		if md != markdown {
+			t.Errorf("%s.md links to %q but %s.markdown to %q", name, md, name, markdown)
+		}
+	}
+}
@@ -1,30 +1,152 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"html"
 	"html/template"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/russross/blackfriday/v2"
+	"golang.org/x/sync/errgroup"
 )
 
 type Exercise struct {
-	Number      int
-	Title       string
+	// Number is the exercise number readers see, as in "Exercise 3": the
+	// numeric prefix of the filename, so it agrees with the exercise's own
+	// heading. The introduction, 00-introduction-setup, is exercise 0.
+	// Files without a prefix are numbered by their Index
+	Number int
+	// Index is the exercise's zero-based position in the workshop order,
+	// for anything that counts pages rather than naming an exercise
+	Index int
+	Slug  string
+	Title string
+	// Name is the exercise filename without extension, e.g.
+	// "02-scanner-arrow-operator"; it is the same in every language
+	Name string
+	// Group is the top-level subdirectory the exercise lives in, if any
+	Group string
+	// Emoji is an optional icon shown on the index card
+	Emoji       string
 	Description string
-	Filename    string
-	Content     template.HTML
-	PrevLink    string
-	NextLink    string
+	// ReadingTime is the estimated reading time in minutes
+	ReadingTime int
+	// WordCount is the number of words in the text, leaving out code
+	// blocks and HTML
+	WordCount int
+	// Authors wrote the exercise, from the front matter
+	Authors []string
+	// Versions switches to the page in the other -versions builds
+	Versions []versionLink
+	// EditURL opens the markdown source in the repository's editor, empty
+	// without -repo-url
+	EditURL string
+	// SourceURL downloads the copy of the markdown source next to the
+	// page, empty without -include-source
+	SourceURL string
+	// EstimatedMinutes is the hands-on time from the front matter, zero
+	// when the author gave none
+	EstimatedMinutes int
+	// Difficulty is beginner, intermediate, advanced or empty, and
+	// DifficultyName its translated label
+	Difficulty     string
+	DifficultyName string
+	// Tags group exercises by topic, e.g. "scanner" or "runtime"
+	Tags []string
+	// TagLinks are the Tags as chips linking to each tag's page
+	TagLinks []tagLink
+	Filename string
+	// URL is the page URL relative to the language directory
+	URL string
+	// AbsoluteURL is the page's full URL under -base-url, empty without one
+	AbsoluteURL string
+	// StructuredData is the page's JSON-LD description, set when the page
+	// is generated
+	StructuredData template.JS
+	Content        template.HTML
+	// TOC is the nested table of contents, empty for short pages
+	TOC      template.HTML
+	PrevLink string
+	NextLink string
+	// PrevTitle and NextTitle are the titles of the adjacent exercises,
+	// empty when the link goes to the index or there is no next page
+	PrevTitle   string
+	NextTitle   string
 	Lang        string
 	AltLangURL  string
 	AltLangName string
 	CSSPath     string
-	HomePath    string
+	// HomePath is the relative path back to the language directory
+	HomePath string
+	// HomeURL links to the language's index page
+	HomeURL       string
+	GoVersion     string
+	GoVersionNote string
+	// CopyFeedbackMs is how long the copy button stays in its success state
+	CopyFeedbackMs int64
+	Environment    string
+	// Objectives and Takeaways come from the exercise front matter
+	Objectives []string
+	Takeaways  []string
+	// Prerequisites and Related are the names of exercises to do first
+	// and of thematically related ones, from the front matter
+	Prerequisites []string
+	Related       []string
+	// SiteRoot is the relative path from the page to the output root
+	SiteRoot string
+	// HasWasm is set when the page embeds runnable WASM examples
+	HasWasm bool
+	// HasMermaid is set when the page has Mermaid diagrams, so only those
+	// pages load the library
+	HasMermaid bool
+	// HasMath is set when the page has formulas, so only those pages load
+	// KaTeX
+	HasMath bool
+	// Favicon links the -favicon copy, or is the inline default icon
+	Favicon template.URL
+	// PWA is set with -pwa, to link the web app manifest and register the
+	// service worker
+	PWA bool
+	// LastUpdated is the modification time of the markdown source, or its
+	// last commit time with -git-dates
+	LastUpdated time.Time
+	// AssetBase is the CDN or local vendor prefix for third-party assets
+	AssetBase string
+
+	// pageHTML is the rendered markdown before links are adapted to the
+	// URL policy, for reuse in all.html
+	pageHTML string
+	// sourcePath is the markdown file the exercise was rendered from
+	sourcePath string
+}
+
+// ExercisePageData is what the exercise template is executed with: the
+// page's exercise plus every exercise of the language for the sidebar.
+type ExercisePageData struct {
+	Exercise
+	All []Exercise
+	// PrerequisiteExercises and RelatedExercises are the exercises
+	// Prerequisites and Related name, resolved from All
+	PrerequisiteExercises []Exercise
+	RelatedExercises      []Exercise
+	// Total is the number of the last exercise, for "Exercise 3 of 11".
+	// The introduction is exercise 0, so it doesn't count towards it, and
+	// Percent is how far along the page's exercise is
+	Total   int
+	Percent int
 }
 
 type IndexData struct {
@@ -37,9 +159,10 @@ type IndexData struct {
 }
 
 type exerciseMeta struct {
-	Filename    string
-	Title       string
-	Description string
+	Filename    string `yaml:"filename"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Emoji       string `yaml:"emoji"`
 }
 
 type LangConfig struct {
@@ -48,8 +171,17 @@ type LangConfig struct {
 	OutputPrefix  string // "" for English, "es" for Spanish
 	AltLangPrefix string // "es" for English, "" for Spanish
 	AltLangName   string // "Español" for English, "English" for Spanish
-	Metadata      []exerciseMeta
-	UIStrings     UIStrings
+	// SourceDir is the language's folder inside the exercises directory,
+	// e.g. "es", when exercises are split per language; empty for the flat
+	// NN-name.md / NN-name.es.md layout. It is set by withSourceDir.
+	SourceDir string
+	// exercisesDir is where sourceFile looks for a .markdown file in place
+	// of the .md one; set by withSourceDir
+	exercisesDir string
+	// Metadata overrides the titles and descriptions of discovered
+	// exercises; files without an entry still get a page
+	Metadata  []exerciseMeta
+	UIStrings UIStrings
 }
 
 type UIStrings struct {
@@ -85,6 +217,31 @@ type UIStrings struct {
 	CTAButton           string
 	FooterTitle         string
 	FooterCreatedBy     string
+	GoVersionBanner     string
+	WorkshopMap         string
+	WorkshopMapLink     string
+	SearchPlaceholder   string
+	SearchNoResults     string
+	Difficulties        map[string]string
+	EstimatedTotal      string
+	FilterByTag         string
+	TaggedTitle         string
+	ToggleTheme         string
+	SkipToContent       string
+	MainNavigation      string
+	Pagination          string
+	ProgressText        string
+	PageStatus          string
+	ResetProgress       string
+	Contents            string
+	ClearFilters        string
+	SortBy              string
+	SortNumber          string
+	SortDifficulty      string
+	SortTime            string
+	NotFoundTitle       string
+	NotFoundText        string
+	NotFoundBack        string
 }
 
 var englishConfig = LangConfig{
@@ -94,18 +251,18 @@ var englishConfig = LangConfig{
 	AltLangPrefix: "es",
 	AltLangName:   "Español",
 	Metadata: []exerciseMeta{
-		{"00-introduction-setup", "Introduction and Setup", "Get started by cloning and setting up the Go source code environment."},
-		{"01-compile-go-unchanged", "Compiling Go Without Changes", "Learn to build the Go toolchain from source without any modifications."},
-		{"02-scanner-arrow-operator", "Adding the \"=>\" Arrow Operator for Goroutines", "Learn scanner/lexer modification by adding \"=>\" as an alternative syntax for starting goroutines."},
-		{"03-parser-multiple-go", "Multiple \"go\" Keywords - Parser Enhancement", "Learn parser modification by enabling multiple consecutive \"go\" keywords (go go go myFunction)."},
-		{"04-compiler-inlining-parameters", "Inline Parameters - Function Inlining Experiments", "Explore the inliner behavior by modifying function inlining parameters."},
-		{"05-gofmt-ast-transformation", "gofmt Modification - Indentation & AST Transformation", "Modify gofmt to use 4 spaces instead of tabs and add a custom AST transformation replacing \"hello\" with \"helo\"."},
-		{"06-ssa-power-of-two-detector", "SSA Pass - Detecting Division by Powers of Two", "Create a custom SSA compiler pass that detects division operations by powers of two that could be optimized to bit shifts."},
-		{"07-runtime-patient-go", "Patient Go - Making Go Wait for Goroutines", "Modify the Go runtime to wait for all goroutines to complete before program termination."},
-		{"08-goroutine-sleep-detective", "Goroutine Sleep Detective - Runtime State Monitoring", "Add logging to the Go scheduler to monitor goroutines going to sleep."},
-		{"09-predictable-select", "Predictable Select - Removing Randomness from Go's Select Statement", "Modify Go's select statement implementation to be deterministic instead of random."},
-		{"10-java-style-stack-traces", "Java-Style Stack Traces - Making Go Panics Look Familiar", "Transform Go's verbose stack traces into Java-style formatting."},
-		{"11-dnd-work-stealing", "D&D Work Stealing - Rolling for Goroutines", "Add a d20 dice roll to Go's work stealing scheduler to gate goroutine theft between processors."},
+		{"00-introduction-setup", "Introduction and Setup", "Get started by cloning and setting up the Go source code environment.", ""},
+		{"01-compile-go-unchanged", "Compiling Go Without Changes", "Learn to build the Go toolchain from source without any modifications.", ""},
+		{"02-scanner-arrow-operator", "Adding the \"=>\" Arrow Operator for Goroutines", "Learn scanner/lexer modification by adding \"=>\" as an alternative syntax for starting goroutines.", ""},
+		{"03-parser-multiple-go", "Multiple \"go\" Keywords - Parser Enhancement", "Learn parser modification by enabling multiple consecutive \"go\" keywords (go go go myFunction).", ""},
+		{"04-compiler-inlining-parameters", "Inline Parameters - Function Inlining Experiments", "Explore the inliner behavior by modifying function inlining parameters.", ""},
+		{"05-gofmt-ast-transformation", "gofmt Modification - Indentation & AST Transformation", "Modify gofmt to use 4 spaces instead of tabs and add a custom AST transformation replacing \"hello\" with \"helo\".", ""},
+		{"06-ssa-power-of-two-detector", "SSA Pass - Detecting Division by Powers of Two", "Create a custom SSA compiler pass that detects division operations by powers of two that could be optimized to bit shifts.", ""},
+		{"07-runtime-patient-go", "Patient Go - Making Go Wait for Goroutines", "Modify the Go runtime to wait for all goroutines to complete before program termination.", ""},
+		{"08-goroutine-sleep-detective", "Goroutine Sleep Detective - Runtime State Monitoring", "Add logging to the Go scheduler to monitor goroutines going to sleep.", ""},
+		{"09-predictable-select", "Predictable Select - Removing Randomness from Go's Select Statement", "Modify Go's select statement implementation to be deterministic instead of random.", ""},
+		{"10-java-style-stack-traces", "Java-Style Stack Traces - Making Go Panics Look Familiar", "Transform Go's verbose stack traces into Java-style formatting.", ""},
+		{"11-dnd-work-stealing", "D&D Work Stealing - Rolling for Goroutines", "Add a d20 dice roll to Go's work stealing scheduler to gate goroutine theft between processors.", ""},
 	},
 	UIStrings: UIStrings{
 		Home:            "Home",
@@ -114,7 +271,7 @@ var englishConfig = LangConfig{
 		Exercise:        "Exercise",
 		HeroTitle:       "Having fun with the Go Source Code",
 		HeroLead:        "Welcome to an interactive workshop where you'll learn how to modify and experiment with the Go programming language source code! This hands-on workshop will guide you through understanding, building, and making changes to the Go compiler and runtime.",
-		HeroVersionNote: "<strong>This workshop uses Go version 1.26.1</strong> - we'll check out the specific release tag to ensure consistency across all exercises.",
+		HeroVersionNote: "<strong>This workshop uses Go version %s</strong> - we'll check out the specific release tag to ensure consistency across all exercises.",
 		Prerequisites:   "Prerequisites",
 		PrereqItems: []string{
 			"Basic knowledge of Go programming",
@@ -127,7 +284,7 @@ var englishConfig = LangConfig{
 		OverviewText:   "This workshop consists of %d exercises that will take you through the process from building Go from source, and making modifications at different places in the compiler, tooling and runtime. You'll gain some insights about the Go internals, from things like the lexer or parser, to runtime behaviors:",
 		GettingStarted: "Getting Started",
 		GettingStartedItems: []string{
-			`Start with <a href="%s00-introduction-setup.html">Exercise 0</a> to set up your environment`,
+			`Start with <a href="%s">Exercise 0</a> to set up your environment`,
 			"Work through the exercises in order",
 			"After exercise 1, you can pick and choose the exercise that you want.",
 		},
@@ -162,11 +319,40 @@ var englishConfig = LangConfig{
 			"Build custom language variants and tools",
 			"Understand some trade-offs in language and runtime design",
 		},
-		Contributing:     "Contributing",
-		ContributingText: `Found an issue, have an improvement idea or want to add more exercises? Please <a href="https://github.com/jespino/having-fun-with-the-go-source-code-workshop/issues">open an issue</a> or submit a pull request!`,
-		CTAButton:        "Start with Exercise 0 →",
-		FooterTitle:      "Having fun with the Go Source Code",
-		FooterCreatedBy:  "Created by <strong>Jesús Espino</strong>",
+		Contributing:      "Contributing",
+		ContributingText:  `Found an issue, have an improvement idea or want to add more exercises? Please <a href="https://github.com/jespino/having-fun-with-the-go-source-code-workshop/issues">open an issue</a> or submit a pull request!`,
+		CTAButton:         "Start with Exercise 0 →",
+		FooterTitle:       "Having fun with the Go Source Code",
+		FooterCreatedBy:   "Created by <strong>Jesús Espino</strong>",
+		GoVersionBanner:   "These exercises target Go %s; your line numbers may differ on other versions.",
+		EstimatedTotal:    "🛠️ Estimated hands-on time: ~%s",
+		WorkshopMap:       "Workshop Map",
+		WorkshopMapLink:   "Open the map as an image",
+		SearchPlaceholder: "Search exercises…",
+		SearchNoResults:   "No exercises match your search.",
+		FilterByTag:       "Filter by topic:",
+		TaggedTitle:       "Exercises about %s",
+		ClearFilters:      "Clear filters",
+		SortBy:            "Sort by:",
+		SortNumber:        "Exercise number",
+		SortDifficulty:    "Difficulty",
+		SortTime:          "Estimated time",
+		ToggleTheme:       "Toggle theme",
+		SkipToContent:     "Skip to content",
+		MainNavigation:    "Main",
+		Pagination:        "Pages",
+		ProgressText:      "{done} of {total} exercises complete",
+		PageStatus:        "Page {page} of {pages}",
+		ResetProgress:     "Reset progress",
+		Contents:          "Contents",
+		NotFoundTitle:     "Page not found",
+		NotFoundText:      "The page you are looking for doesn't exist or has moved. Try searching for it, or pick one of the exercises.",
+		NotFoundBack:      "← Back to the workshop home",
+		Difficulties: map[string]string{
+			"beginner":     "Beginner",
+			"intermediate": "Intermediate",
+			"advanced":     "Advanced",
+		},
 	},
 }
 
@@ -177,18 +363,18 @@ var spanishConfig = LangConfig{
 	AltLangPrefix: "",
 	AltLangName:   "English",
 	Metadata: []exerciseMeta{
-		{"00-introduction-setup", "Introducción y Configuración", "Comienza clonando y configurando el entorno del código fuente de Go."},
-		{"01-compile-go-unchanged", "Compilando Go Sin Cambios", "Aprende a compilar el toolchain de Go desde el código fuente sin modificaciones."},
-		{"02-scanner-arrow-operator", "Añadiendo el Operador Flecha \"=>\" para Goroutines", "Aprende a modificar el scanner/lexer añadiendo \"=>\" como sintaxis alternativa para iniciar goroutines."},
-		{"03-parser-multiple-go", "Múltiples Keywords \"go\" - Mejora del Parser", "Aprende a modificar el parser permitiendo múltiples keywords \"go\" consecutivos (go go go myFunction)."},
-		{"04-compiler-inlining-parameters", "Parámetros de Inlining - Experimentos con Function Inlining", "Explora el comportamiento del inliner modificando los parámetros de inlining de funciones."},
-		{"05-gofmt-ast-transformation", "Modificación de gofmt - Indentación y Transformación AST", "Modifica gofmt para usar 4 espacios en lugar de tabs y añade una transformación AST personalizada reemplazando \"hello\" con \"helo\"."},
-		{"06-ssa-power-of-two-detector", "Pase SSA - Detectando División por Potencias de Dos", "Crea un pase SSA personalizado en el compilador que detecta operaciones de división por potencias de dos que podrían optimizarse con bit shifts."},
-		{"07-runtime-patient-go", "Go Paciente - Haciendo que Go Espere a las Goroutines", "Modifica el runtime de Go para esperar a que todas las goroutines terminen antes de finalizar el programa."},
-		{"08-goroutine-sleep-detective", "Detective de Goroutines Dormidas - Monitoreo del Estado del Runtime", "Añade logging al scheduler de Go para monitorear goroutines que se van a dormir."},
-		{"09-predictable-select", "Select Predecible - Eliminando la Aleatoriedad del Select de Go", "Modifica la implementación del select de Go para que sea determinista en lugar de aleatorio."},
-		{"10-java-style-stack-traces", "Stack Traces Estilo Java - Haciendo los Panics de Go Familiares", "Transforma los stack traces verbosos de Go al formato estilo Java."},
-		{"11-dnd-work-stealing", "D&D Work Stealing - Tirando Dados por Goroutines", "Añade una tirada de dado d20 al algoritmo de work stealing del planificador de Go para controlar los robos de goroutines entre procesadores."},
+		{"00-introduction-setup", "Introducción y Configuración", "Comienza clonando y configurando el entorno del código fuente de Go.", ""},
+		{"01-compile-go-unchanged", "Compilando Go Sin Cambios", "Aprende a compilar el toolchain de Go desde el código fuente sin modificaciones.", ""},
+		{"02-scanner-arrow-operator", "Añadiendo el Operador Flecha \"=>\" para Goroutines", "Aprende a modificar el scanner/lexer añadiendo \"=>\" como sintaxis alternativa para iniciar goroutines.", ""},
+		{"03-parser-multiple-go", "Múltiples Keywords \"go\" - Mejora del Parser", "Aprende a modificar el parser permitiendo múltiples keywords \"go\" consecutivos (go go go myFunction).", ""},
+		{"04-compiler-inlining-parameters", "Parámetros de Inlining - Experimentos con Function Inlining", "Explora el comportamiento del inliner modificando los parámetros de inlining de funciones.", ""},
+		{"05-gofmt-ast-transformation", "Modificación de gofmt - Indentación y Transformación AST", "Modifica gofmt para usar 4 espacios en lugar de tabs y añade una transformación AST personalizada reemplazando \"hello\" con \"helo\".", ""},
+		{"06-ssa-power-of-two-detector", "Pase SSA - Detectando División por Potencias de Dos", "Crea un pase SSA personalizado en el compilador que detecta operaciones de división por potencias de dos que podrían optimizarse con bit shifts.", ""},
+		{"07-runtime-patient-go", "Go Paciente - Haciendo que Go Espere a las Goroutines", "Modifica el runtime de Go para esperar a que todas las goroutines terminen antes de finalizar el programa.", ""},
+		{"08-goroutine-sleep-detective", "Detective de Goroutines Dormidas - Monitoreo del Estado del Runtime", "Añade logging al scheduler de Go para monitorear goroutines que se van a dormir.", ""},
+		{"09-predictable-select", "Select Predecible - Eliminando la Aleatoriedad del Select de Go", "Modifica la implementación del select de Go para que sea determinista en lugar de aleatorio.", ""},
+		{"10-java-style-stack-traces", "Stack Traces Estilo Java - Haciendo los Panics de Go Familiares", "Transforma los stack traces verbosos de Go al formato estilo Java.", ""},
+		{"11-dnd-work-stealing", "D&D Work Stealing - Tirando Dados por Goroutines", "Añade una tirada de dado d20 al algoritmo de work stealing del planificador de Go para controlar los robos de goroutines entre procesadores.", ""},
 	},
 	UIStrings: UIStrings{
 		Home:            "Inicio",
@@ -197,7 +383,7 @@ var spanishConfig = LangConfig{
 		Exercise:        "Ejercicio",
 		HeroTitle:       "Divirtiéndonos con el Código Fuente de Go",
 		HeroLead:        "¡Bienvenido a un taller interactivo donde aprenderás a modificar y experimentar con el código fuente del lenguaje de programación Go! Este taller práctico te guiará a través de la comprensión, compilación y modificación del compilador y runtime de Go.",
-		HeroVersionNote: "<strong>Este taller usa Go versión 1.26.1</strong> - haremos checkout del tag de release específico para asegurar consistencia en todos los ejercicios.",
+		HeroVersionNote: "<strong>Este taller usa Go versión %s</strong> - haremos checkout del tag de release específico para asegurar consistencia en todos los ejercicios.",
 		Prerequisites:   "Prerrequisitos",
 		PrereqItems: []string{
 			"Conocimientos básicos de programación en Go",
@@ -210,7 +396,7 @@ var spanishConfig = LangConfig{
 		OverviewText:   "Este taller consta de %d ejercicios que te llevarán a través del proceso desde compilar Go desde el código fuente hasta hacer modificaciones en diferentes partes del compilador, herramientas y runtime. Obtendrás conocimientos sobre los internos de Go, desde cosas como el lexer o parser, hasta comportamientos del runtime:",
 		GettingStarted: "Cómo Empezar",
 		GettingStartedItems: []string{
-			`Comienza con el <a href="%s00-introduction-setup.html">Ejercicio 0</a> para configurar tu entorno`,
+			`Comienza con el <a href="%s">Ejercicio 0</a> para configurar tu entorno`,
 			"Trabaja los ejercicios en orden",
 			"Después del ejercicio 1, puedes elegir el ejercicio que quieras.",
 		},
@@ -245,526 +431,1518 @@ var spanishConfig = LangConfig{
 			"Construir variantes personalizadas del lenguaje y herramientas",
 			"Entender algunas decisiones de diseño del lenguaje y runtime",
 		},
-		Contributing:     "Contribuir",
-		ContributingText: `¿Encontraste un problema, tienes una idea de mejora o quieres añadir más ejercicios? ¡Por favor <a href="https://github.com/jespino/having-fun-with-the-go-source-code-workshop/issues">abre un issue</a> o envía un pull request!`,
-		CTAButton:        "Comenzar con el Ejercicio 0 →",
-		FooterTitle:      "Divirtiéndonos con el Código Fuente de Go",
-		FooterCreatedBy:  "Creado por <strong>Jesús Espino</strong>",
+		Contributing:      "Contribuir",
+		ContributingText:  `¿Encontraste un problema, tienes una idea de mejora o quieres añadir más ejercicios? ¡Por favor <a href="https://github.com/jespino/having-fun-with-the-go-source-code-workshop/issues">abre un issue</a> o envía un pull request!`,
+		CTAButton:         "Comenzar con el Ejercicio 0 →",
+		FooterTitle:       "Divirtiéndonos con el Código Fuente de Go",
+		FooterCreatedBy:   "Creado por <strong>Jesús Espino</strong>",
+		GoVersionBanner:   "Estos ejercicios están pensados para Go %s; los números de línea pueden variar en otras versiones.",
+		EstimatedTotal:    "🛠️ Tiempo práctico estimado: ~%s",
+		WorkshopMap:       "Mapa del Taller",
+		WorkshopMapLink:   "Abrir el mapa como imagen",
+		SearchPlaceholder: "Buscar en los ejercicios…",
+		SearchNoResults:   "Ningún ejercicio coincide con tu búsqueda.",
+		FilterByTag:       "Filtrar por tema:",
+		TaggedTitle:       "Ejercicios sobre %s",
+		ClearFilters:      "Quitar filtros",
+		SortBy:            "Ordenar por:",
+		SortNumber:        "Número de ejercicio",
+		SortDifficulty:    "Dificultad",
+		SortTime:          "Tiempo estimado",
+		ToggleTheme:       "Cambiar tema",
+		SkipToContent:     "Saltar al contenido",
+		MainNavigation:    "Principal",
+		Pagination:        "Páginas",
+		ProgressText:      "{done} de {total} ejercicios completados",
+		PageStatus:        "Página {page} de {pages}",
+		ResetProgress:     "Reiniciar progreso",
+		Contents:          "Contenido",
+		NotFoundTitle:     "Página no encontrada",
+		NotFoundText:      "La página que buscas no existe o se ha movido. Prueba a buscarla o elige uno de los ejercicios.",
+		NotFoundBack:      "← Volver al inicio del taller",
+		Difficulties: map[string]string{
+			"beginner":     "Principiante",
+			"intermediate": "Intermedio",
+			"advanced":     "Avanzado",
+		},
 	},
 }
 
 var languages = []LangConfig{englishConfig, spanishConfig}
 
-// exerciseMetadata is kept for backward compatibility with serve.go
+// exerciseMetadata is kept for backward compatibility
 var exerciseMetadata = englishConfig.Metadata
 
+// defaultGoVersion is the Go release the exercises are written against.
+const defaultGoVersion = "1.26.1"
+
+// buildOptions holds the settings that affect how pages are rendered.
+type buildOptions struct {
+	GoVersion    string
+	CopyFeedback time.Duration
+	// Environment is empty for production builds and names the
+	// environment (e.g. "staging") otherwise.
+	Environment string
+	// Transforms are find/replace rules applied to every exercise; nil
+	// when no transforms file was given.
+	Transforms *transformSet
+	// Strict turns content warnings into build errors.
+	Strict bool
+	// URLs decides output file layout and internal link spelling.
+	URLs urlPolicy
+	// Languages are the languages to build, in order.
+	Languages []LangConfig
+	// Exercises replaces the compiled-in exercise metadata when a -config
+	// file was given; languages it doesn't list are discovered on disk.
+	Exercises exerciseConfig
+	// BaseURL is the absolute URL the site is published at, e.g.
+	// "https://example.com/workshop/"; empty when unknown.
+	BaseURL string
+	// TOCDepth is the deepest heading level listed in the table of
+	// contents; below 2 disables it.
+	TOCDepth int
+	// GroupByDir groups the index cards by exercise subdirectory.
+	GroupByDir bool
+	// SinglePage also writes all.html with every exercise on one page.
+	SinglePage bool
+	// RepoURL is the repository exercise pages link to for editing their
+	// source on RepoBranch; empty for no link.
+	RepoURL    string
+	RepoBranch string
+	// PerPage is how many exercise cards the index shows at a time; 0
+	// shows them all.
+	PerPage int
+	// Version is the version directory being built in the -versions
+	// layout, e.g. "v1.25", and Versions all of them, newest first; both
+	// are empty otherwise.
+	Version  string
+	Versions []string
+	// Favicon is the icon file given with -favicon, copied to the output
+	// root; without one pages inline defaultFavicon.
+	Favicon string
+	// Offline serves Font Awesome from a local copy in the output
+	// directory instead of the CDN.
+	Offline bool
+	// Templates are the page templates and stylesheet, built in or loaded
+	// from the files given with -exercise-template, -index-template and
+	// -css.
+	Templates siteTemplates
+	// Wasm compiles {{wasm "..."}} examples into runnable WebAssembly.
+	Wasm bool
+	// Minify minifies the exercise and index pages and the stylesheet.
+	Minify bool
+	// Math treats $...$ and $$...$$ in the markdown as formulas. ```math
+	// blocks are formulas either way.
+	Math bool
+	// CodeLineNumbers numbers the lines of highlighted code blocks.
+	CodeLineNumbers bool
+	// GitDates dates exercises by their last commit rather than their
+	// modification time, which a fresh clone resets.
+	GitDates bool
+	// Playground adds a Run link to every complete Go program, sharing
+	// it with the Go Playground at build time.
+	Playground bool
+	// Jobs is how many exercise pages are rendered at once.
+	Jobs int
+	// DryRun renders the exercise and index pages and the stylesheet
+	// without writing anything, logging what would be written instead.
+	DryRun bool
+	// Incremental skips exercise pages whose inputs haven't changed since
+	// the last incremental build.
+	Incremental bool
+	// Gzip writes a precompressed .gz next to every generated HTML, CSS
+	// and JSON file.
+	Gzip bool
+	// IncludeSource copies each exercise's markdown next to its page, with
+	// a download button on the page.
+	IncludeSource bool
+	// EPUB is the file to write the first language's exercises to as an
+	// e-book, or empty for none.
+	EPUB string
+	// OutputFormat is outputFormatHTML for the site, or outputFormatJSON
+	// for the exercises as JSON data.
+	OutputFormat string
+	// PWA makes the site an installable web app that works offline, with
+	// a manifest using pwaIcons and a precaching service worker.
+	PWA bool
+
+	pwaIcons []pwaIcon
+	// versionsDir is the exercises directory holding the version
+	// directories
+	versionsDir string
+	// editBase is where edit links to exercise sources start, set from
+	// RepoURL for each build
+	editBase string
+	wasm     *wasmBuilder
+	assets   *exerciseAssets
+	minifier *siteMinifier
+	cache    *buildCache
+	// gitDates holds the commit times when GitDates is set.
+	gitDates map[string]time.Time
+	// playground shares snippets when Playground is set.
+	playground *playgroundLinks
+	// state, when set, records each build for incremental rebuilds.
+	state *siteState
+	// diags collects problems found during the build for the summary.
+	diags *diagnostics
+}
+
 func main() {
 	exercisesDir := flag.String("exercises", "../exercises", "Path to exercises directory")
 	outputDir := flag.String("output", "../website", "Path to output directory")
-	serve := flag.Bool("serve", false, "Start dev server with live reload")
-	port := flag.Int("port", 8000, "Dev server port (used with -serve)")
+	watch := flag.Bool("watch", false, "After building, rebuild whenever an exercise or template file changes")
+	var serveAddr serveFlag
+	flag.Var(&serveAddr, "serve", "After building, serve the output directory, on -port or on the address given as -serve=ADDR (e.g. -serve=:8080); with -watch, pages reload after every rebuild")
+	port := flag.Int("port", 8000, "Port to serve on with -serve when it has no address")
+	goVersion := flag.String("go-version", defaultGoVersion, "Go version the exercises target (shown in the version banner)")
+	copyFeedback := flag.Duration("copy-feedback", 2*time.Second, "How long the copy button shows its success state")
+	env := flag.String("env", "production", "Build environment: staging or production")
+	reviewBase := flag.String("review-base", "", "Render exercises changed since this git ref side by side into review.html")
+	checkLinks := flag.Bool("check-links", false, "Check that internal links and #fragments in the generated pages resolve, listing every broken one")
+	checkExternal := flag.Bool("check-external", false, "Check external links in the generated pages")
+	externalConcurrency := flag.Int("external-concurrency", 8, "Maximum concurrent requests when checking external links")
+	externalTimeout := flag.Duration("external-timeout", 10*time.Second, "Timeout for each external link request")
+	externalCacheTTL := flag.Duration("external-cache-ttl", 24*time.Hour, "How long cached external link results stay valid")
+	transformsFile := flag.String("transforms", "", "YAML file with regex find/replace rules applied to every exercise")
+	trailingSlash := flag.String("trailing-slash", "", "Clean URL policy: always (NN-name/) or never (NN-name); default keeps .html URLs")
+	urlStyle := flag.String("url-style", "", "Output file layout: flat (NN-name.html) or pretty (NN-name/index.html, linked as NN-name/); same as -trailing-slash always")
+	wasm := flag.Bool("wasm", false, "Compile {{wasm \"file.go\"}} examples to WebAssembly with a Run button")
+	refreshSRIFlag := flag.Bool("refresh-sri", false, "Download the CDN assets, record their integrity hashes in sri.json and use them for this build")
+	gitDates := flag.Bool("git-dates", false, "Date exercises by their last git commit instead of the file modification time")
+	codeLineNumbers := flag.Bool("code-line-numbers", false, "Show line numbers in highlighted code blocks")
+	playground := flag.Bool("playground", false, "Add a Run link to Go code blocks holding a complete program, sharing them with the Go Playground")
+	math := flag.Bool("math", false, "Render $...$ and $$...$$ in the exercises as formulas with KaTeX")
+	verbose := flag.Bool("verbose", false, "Print every diagnostic instead of only the per-category counts")
+	diagnosticsJSON := flag.String("diagnostics-json", "", "Also write all diagnostics as JSON to this file")
+	langCodes := flag.String("lang", "", "Comma-separated languages to build, e.g. es or en,es (default all)")
+	configFile := flag.String("config", "", "YAML file listing the exercises per language, replacing the compiled-in metadata")
+	baseURL := flag.String("base-url", "", "Absolute URL the site is published at, used for sitemap.xml, feeds and og:url (e.g. https://example.com/workshop/)")
+	exerciseTemplateFile := flag.String("exercise-template", "", "HTML template file to use instead of the built-in exercise page template")
+	indexTemplateFile := flag.String("index-template", "", "HTML template file to use instead of the built-in index page template")
+	cssFile := flag.String("css", "", "Stylesheet to use instead of the built-in style.css")
+	groupByDir := flag.Bool("group-by-dir", false, "Group index cards by exercise subdirectory, with a heading per group")
+	singlePage := flag.Bool("single-page", false, "Also generate all.html with every exercise in one document")
+	offline := flag.Bool("offline", false, "Serve Font Awesome from local copies so the site works without network access")
+	dryRun := flag.Bool("dry-run", false, "Render the exercise and index pages and the stylesheet, logging what would be written without touching the output directory")
+	incremental := flag.Bool("incremental", false, "Only regenerate exercise pages whose content or template changed since the last -incremental build")
+	jobs := flag.Int("jobs", runtime.GOMAXPROCS(0), "Number of exercise pages to render in parallel")
+	tocDepth := flag.Int("toc-depth", 3, "Deepest heading level in the per-page table of contents (2-6, 0 disables it)")
+	minifyOutput := flag.Bool("minify", false, "Minify the generated pages and stylesheet")
+	includeSource := flag.Bool("include-source", false, "Copy each exercise's markdown next to its page, e.g. 02-scanner-arrow-operator.md, with a button to download it")
+	gzipFlag := flag.Bool("gzip", false, "Also write a best-compression .gz of every generated .html, .css and .json file for hosts that serve precompressed files")
+	perPage := flag.Int("per-page", 0, "Exercise cards per page on the index, with previous/next page controls (0 shows all)")
+	outputFormat := flag.String("output-format", outputFormatHTML, "Output format: html for the site, or json for a JSON file per exercise and index.json for other front-ends")
+	epub := flag.String("epub", "", "Also write the exercises of the first language built to this EPUB file, e.g. workshop.epub")
+	versionsFlag := flag.Bool("versions", false, "Build each version directory of the exercises directory, e.g. v1.25, into the same directory of the output, with a version switcher")
+	repoURL := flag.String("repo-url", "", "Repository URL, e.g. https://github.com/user/repo, for an \"Edit this page on GitHub\" link on every exercise")
+	repoBranch := flag.String("repo-branch", "main", "Branch the -repo-url edit links open")
+	favicon := flag.String("favicon", "", "Icon file (.ico, .png, .svg or .gif) to copy into the output directory and link from every page; a built-in one is inlined otherwise")
+	pwa := flag.Bool("pwa", false, "Generate a web app manifest and a service worker so the workshop can be installed and read offline (needs -base-url and -pwa-icons)")
+	pwaIcons := flag.String("pwa-icons", "", "Comma-separated PNG app icons for -pwa, e.g. icon-192.png,icon-512.png")
+	strict := flag.Bool("strict", false, "Fail the build on content problems such as missing objectives or takeaways, or unregistered exercise files")
 	flag.Parse()
+	serve := serveAddr.address(*port)
+
+	// -serve used to take its address as a separate argument, which now
+	// ends the flags
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument %q (give -serve an address as -serve=ADDR)\n", flag.Arg(0))
+		os.Exit(1)
+	}
 
-	if *serve {
-		srv := newDevServer(*exercisesDir, *outputDir, *port)
-		if err := srv.run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+	if *env != "production" && *env != "staging" {
+		fmt.Fprintf(os.Stderr, "Error: invalid -env %q (want staging or production)\n", *env)
+		os.Exit(1)
+	}
+
+	// Every input is checked before anything is written, including the
+	// hashes -refresh-sri records
+	err := preflight(preflightInputs{
+		ExercisesDir:     *exercisesDir,
+		ConfigFile:       *configFile,
+		TransformsFile:   *transformsFile,
+		ExerciseTemplate: *exerciseTemplateFile,
+		IndexTemplate:    *indexTemplateFile,
+		CSSFile:          *cssFile,
+		Favicon:          *favicon,
+		PWAIcons:         *pwaIcons,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *refreshSRIFlag {
+		if err := refreshSRI(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		return
+	}
+	for _, asset := range missingSRIHashes() {
+		fmt.Fprintf(os.Stderr, "⚠️  No integrity hash recorded for %s; run -refresh-sri with network access\n", asset)
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+	opts := buildOptions{
+		GoVersion:    *goVersion,
+		CopyFeedback: *copyFeedback,
+		Languages:    languages,
+	}
+	if *langCodes != "" {
+		selected, err := selectLanguages(*langCodes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Languages = selected
+	}
+	if *env != "production" {
+		opts.Environment = *env
+	}
+	if *transformsFile != "" {
+		transforms, err := loadTransforms(*transformsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Transforms = transforms
+	}
+	if *configFile != "" {
+		config, err := loadExerciseConfig(*configFile, *exercisesDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Exercises = config
+	}
+	if *tocDepth != 0 && (*tocDepth < 2 || *tocDepth > 6) {
+		fmt.Fprintf(os.Stderr, "Error: invalid -toc-depth %d (want 2-6, or 0 to disable)\n", *tocDepth)
+		os.Exit(1)
+	}
+	opts.TOCDepth = *tocDepth
+	if *perPage < 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid -per-page %d (want 0 or more)\n", *perPage)
+		os.Exit(1)
+	}
+	opts.PerPage = *perPage
+	if *jobs < 1 {
+		fmt.Fprintf(os.Stderr, "Error: invalid -jobs %d (want at least 1)\n", *jobs)
+		os.Exit(1)
+	}
+	opts.Math = *math
+	opts.Playground = *playground
+	opts.CodeLineNumbers = *codeLineNumbers
+	opts.GitDates = *gitDates
+	opts.Jobs = *jobs
+	opts.Incremental = *incremental
+	opts.DryRun = *dryRun
+	if *versionsFlag && (*watch || *reviewBase != "" || *configFile != "" || *epub != "") {
+		fmt.Fprintln(os.Stderr, "Error: -versions can't be combined with -watch, -review-base, -config or -epub")
+		os.Exit(1)
+	}
+	if *outputFormat != outputFormatHTML && *outputFormat != outputFormatJSON {
+		fmt.Fprintf(os.Stderr, "Error: invalid -output-format %q (want html or json)\n", *outputFormat)
+		os.Exit(1)
+	}
+	if *outputFormat == outputFormatJSON && (*pwa || *epub != "" || *reviewBase != "" || *checkLinks || *checkExternal) {
+		fmt.Fprintln(os.Stderr, "Error: -output-format json can't be combined with -pwa, -epub, -review-base, -check-links or -check-external")
+		os.Exit(1)
+	}
+	opts.OutputFormat = *outputFormat
+	if *dryRun && (*watch || serve != "" || *checkLinks || *checkExternal) {
+		fmt.Fprintln(os.Stderr, "Error: -dry-run can't be combined with -watch, -serve, -check-links or -check-external")
+		os.Exit(1)
+	}
+	opts.BaseURL = *baseURL
+	opts.Offline = *offline
+	opts.SinglePage = *singlePage
+	opts.GroupByDir = *groupByDir
+	opts.Minify = *minifyOutput
+	if *favicon != "" && !faviconExts[strings.ToLower(filepath.Ext(*favicon))] {
+		fmt.Fprintf(os.Stderr, "Error: unsupported -favicon %s (want .ico, .png, .svg or .gif)\n", *favicon)
+		os.Exit(1)
+	}
+	opts.Favicon = *favicon
+	opts.RepoURL = *repoURL
+	opts.RepoBranch = *repoBranch
+	opts.Gzip = *gzipFlag
+	opts.IncludeSource = *includeSource
+	opts.EPUB = *epub
+	if *pwa {
+		if *baseURL == "" {
+			fmt.Fprintln(os.Stderr, "Error: -pwa needs -base-url for the app's start URL")
+			os.Exit(1)
+		}
+		icons, err := loadPWAIcons(*pwaIcons)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.PWA = true
+		opts.pwaIcons = icons
+	}
+	templates, err := loadTemplates(*exerciseTemplateFile, *indexTemplateFile, *cssFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.Templates = templates
+	opts.Strict = *strict
+	opts.diags = &diagnostics{}
+	opts.Wasm = *wasm
+	if *urlStyle != "" {
+		if *trailingSlash != "" {
+			fmt.Fprintf(os.Stderr, "Error: -url-style and -trailing-slash can't be combined\n")
+			os.Exit(1)
+		}
+		policy, err := urlStylePolicy(*urlStyle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		*trailingSlash = policy
+	}
+	opts.URLs = urlPolicy{TrailingSlash: *trailingSlash}
+	if err := opts.URLs.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	totalPages := 0
-	for _, lang := range languages {
-		// Determine output directory for this language
-		langOutputDir := *outputDir
-		if lang.OutputPrefix != "" {
-			langOutputDir = filepath.Join(*outputDir, lang.OutputPrefix)
-			if err := os.MkdirAll(langOutputDir, 0o755); err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating output directory for %s: %v\n", lang.Code, err)
+	if *watch {
+		var onRebuild func()
+		if serve != "" {
+			srv := newDevServer(*outputDir, true)
+			ln, err := srv.listen(serve)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 				os.Exit(1)
 			}
+			go func() {
+				if err := srv.serve(ln); err != nil {
+					fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+					os.Exit(1)
+				}
+			}()
+			onRebuild = srv.notifyClients
+		}
+		if err := watchSite(*exercisesDir, *outputDir, opts, onRebuild); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		return
+	}
+
+	if *reviewBase != "" {
+		changed, err := generateReview(*exercisesDir, *outputDir, *reviewBase, opts.Templates.css, opts.Languages)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating review: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📝 %d exercise files changed since %s\n", changed, *reviewBase)
+		fmt.Printf("📁 Output directory: %s\n", *outputDir)
+		return
+	}
+
+	build := buildSite
+	if *versionsFlag {
+		build = buildVersions
+	}
+	totalPages, err := build(*exercisesDir, *outputDir, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Printf("✅ Dry run complete: %d pages rendered, nothing written\n", totalPages)
+	} else {
+		fmt.Println("✅ Website generated successfully!")
+		fmt.Printf("📁 Output directory: %s\n", *outputDir)
+		fmt.Printf("📄 Generated %d pages total (including all languages)\n", totalPages)
+	}
+
+	if *checkLinks {
+		if err := checkInternalLinks(*outputDir, opts.BaseURL, opts.diags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-		// Determine CSS path relative to output dir
-		cssPath := "style.css"
+	if *checkExternal {
+		err := checkExternalLinks(*outputDir, externalCheckOptions{
+			Concurrency: *externalConcurrency,
+			Timeout:     *externalTimeout,
+			CacheFile:   ".linkcache",
+			CacheTTL:    *externalCacheTTL,
+			SiteURL:     opts.BaseURL,
+		}, opts.diags)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// A link check is only useful if it says which links are broken, and
+	// errors fail the build so they are always listed
+	opts.diags.printSummary(*verbose || *checkLinks || opts.diags.errorCount() > 0)
+	if *diagnosticsJSON != "" {
+		if err := opts.diags.writeJSON(*diagnosticsJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if n := opts.diags.errorCount(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d problems found\n", n)
+		os.Exit(1)
+	}
+
+	if serve != "" {
+		srv := newDevServer(*outputDir, false)
+		ln, err := srv.listen(serve)
+		if err == nil {
+			err = srv.serve(ln)
+		}
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// buildSite generates every page for every language and returns the number
+// of pages written.
+func buildSite(exercisesDir, outputDir string, opts buildOptions) (int, error) {
+	// A dry run only renders the pages, leaving the output untouched
+	if opts.DryRun {
+		opts.Offline, opts.Wasm, opts.Incremental, opts.Playground = false, false, false, false
+		fmt.Println("ℹ️  Dry run: rendering pages without writing to the output directory")
+	} else if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	if opts.Offline {
+		if err := downloadAssets(outputDir); err != nil {
+			return 0, fmt.Errorf("fetching offline assets: %w", err)
+		}
+	}
+
+	opts.Transforms.reset()
+	if opts.Minify {
+		opts.minifier = newSiteMinifier()
+	}
+	// A full build leaves pages the cache doesn't know about, so it must
+	// not be trusted afterwards
+	if opts.Incremental {
+		opts.cache = loadBuildCache(outputDir, opts)
+	} else if !opts.DryRun {
+		if err := removeBuildCache(outputDir); err != nil {
+			return 0, err
+		}
+	}
+	if !opts.DryRun {
+		opts.assets = newExerciseAssets(exercisesDir, outputDir, opts.diags)
+	}
+	if opts.Wasm {
+		opts.wasm = newWasmBuilder(exercisesDir, outputDir, opts.diags)
+	}
+	if opts.Playground {
+		opts.playground = newPlaygroundLinks(playgroundCacheFile, opts.diags)
+	}
+	if opts.GitDates {
+		dates, err := loadGitDates(exercisesDir)
+		if err != nil {
+			opts.diags.warnf(categoryBuild, "", "%v, dating exercises by modification time", err)
+		}
+		opts.gitDates = dates
+	}
+	if opts.RepoURL != "" {
+		opts.editBase = editBaseURL(opts.RepoURL, opts.RepoBranch, exercisesDir, opts.diags)
+	}
+	if opts.state != nil {
+		opts.state.opts = opts
+		opts.state.langs = nil
+	}
+
+	totalPages := 0
+	var allExercises []Exercise
+	var notFoundExercises []Exercise
+	for _, lang := range opts.Languages {
+		lang = withSourceDir(exercisesDir, lang)
+		checkLanguageTag(lang, opts.diags)
+
+		// Determine output directory for this language
+		langOutputDir := outputDir
 		if lang.OutputPrefix != "" {
-			cssPath = "../style.css"
+			langOutputDir = filepath.Join(outputDir, lang.OutputPrefix)
+			if !opts.DryRun {
+				if err := os.MkdirAll(langOutputDir, 0o755); err != nil {
+					return 0, fmt.Errorf("creating output directory for %s: %w", lang.Code, err)
+				}
+			}
 		}
 
-		// Determine the home path prefix
-		homePath := ""
+		// Determine the path from the language directory to the output
+		// root, where shared files like the CSS live
+		siteRoot := ""
 		if lang.OutputPrefix != "" {
-			homePath = ""
+			siteRoot = "../"
 		}
 
-		// Determine alt lang URL prefix
-		altLangURLPrefix := "../"
-		if lang.OutputPrefix == "" {
-			altLangURLPrefix = "es/"
+		// Determine alt lang URL prefix; empty when the other language
+		// isn't being built, which hides the language switcher
+		altLangURLPrefix := ""
+		if languageBuilt(lang.AltLangPrefix, opts.Languages) {
+			altLangURLPrefix = "../"
+			if lang.OutputPrefix == "" {
+				altLangURLPrefix = "es/"
+			}
 		}
 
-		// Generate exercise pages
-		exercises := make([]Exercise, 0, len(lang.Metadata))
-		for i, meta := range lang.Metadata {
-			exercise, err := generateExercisePage(*exercisesDir, langOutputDir, lang, meta, i, cssPath, homePath, altLangURLPrefix)
+		// An exercises config replaces the compiled-in metadata entirely;
+		// otherwise find the exercises on disk and let the hardcoded
+		// metadata override titles and descriptions
+		metas, ok := opts.Exercises[lang.Code]
+		registered := metas
+		if !ok {
+			if opts.Exercises != nil {
+				lang.Metadata = nil
+			}
+			registered = lang.Metadata
+			var err error
+			metas, err = discoverExercises(exercisesDir, lang)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error generating exercise %s (%s): %v\n", meta.Filename, lang.Code, err)
-				os.Exit(1)
+				return 0, err
 			}
-			exercises = append(exercises, exercise)
 		}
+		if len(metas) == 0 {
+			return 0, fmt.Errorf("no exercises found in %s for %s", exercisesDir, lang.Code)
+		}
+		if err := checkExerciseFiles(exercisesDir, lang, registered, ok, opts); err != nil {
+			return 0, err
+		}
+		lang.Metadata = metas
+		opts.cache.expect(langOutputDir, lang, opts.URLs)
+
+		// Generate exercise pages. Every page lists all exercises in its
+		// sidebar, so they are all loaded before any page is written
+		exercises, err := loadExercises(exercisesDir, lang, siteRoot, altLangURLPrefix, opts)
+		if err != nil {
+			return 0, err
+		}
+		checkPrerequisites(lang, exercises, opts.diags)
+		linkTags(exercises)
+		if opts.OutputFormat == outputFormatJSON {
+			// The exercises go to other front-ends as data instead of pages
+			if err := generateJSONOutput(langOutputDir, lang, exercises, opts); err != nil {
+				return 0, err
+			}
+		} else {
+			generateExercisePages(langOutputDir, lang, exercises, opts)
 
-		// Generate index page
-		if err := generateIndexPage(langOutputDir, lang, exercises, cssPath, homePath, altLangURLPrefix); err != nil {
-			fmt.Fprintf(os.Stderr, "Error generating index page (%s): %v\n", lang.Code, err)
-			os.Exit(1)
+			// Generate index page
+			if err := generateIndexPage(langOutputDir, lang, exercises, siteRoot, altLangURLPrefix, opts); err != nil {
+				return 0, fmt.Errorf("generating index page (%s): %w", lang.Code, err)
+			}
+
+			if !opts.DryRun {
+				if err := generateLanguageFiles(langOutputDir, lang, exercises, siteRoot, opts); err != nil {
+					return 0, err
+				}
+			}
 		}
 
+		if opts.state != nil {
+			opts.state.langs = append(opts.state.langs, builtLanguage{
+				lang:             lang,
+				outputDir:        langOutputDir,
+				siteRoot:         siteRoot,
+				altLangURLPrefix: altLangURLPrefix,
+				exercises:        exercises,
+			})
+		}
+		if notFoundExercises == nil {
+			notFoundExercises = exercises
+		}
+		allExercises = append(allExercises, exercises...)
 		totalPages += len(exercises) + 1
 	}
 
+	// The rest of the site only makes sense around the HTML pages
+	htmlOutput := opts.OutputFormat != outputFormatJSON
+
 	// Copy CSS file (only at root level, shared by all languages)
-	if err := copyCSSFile(*outputDir); err != nil {
-		fmt.Fprintf(os.Stderr, "Error copying CSS file: %v\n", err)
-		os.Exit(1)
+	if htmlOutput {
+		if err := copyCSSFile(outputDir, opts.Templates.css, opts); err != nil {
+			return 0, fmt.Errorf("copying CSS file: %w", err)
+		}
+		if err := copyFavicon(outputDir, opts); err != nil {
+			return 0, err
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Println("ℹ️  Dry run: skipping the search index, manifest, map, feed, sitemap, EPUB, 404 and single pages")
+	} else if htmlOutput {
+		// Static hosts serve a single 404 page, in the first language built
+		if err := generate404Page(outputDir, opts.Languages[0], notFoundExercises, opts); err != nil {
+			return 0, fmt.Errorf("generating 404 page: %w", err)
+		}
+
+		// The book reads the exercises' images from the generated site
+		if opts.EPUB != "" {
+			if err := generateEPUB(opts.EPUB, outputDir, opts.Languages[0], notFoundExercises, opts); err != nil {
+				return 0, fmt.Errorf("generating EPUB: %w", err)
+			}
+		}
+
+		// Search engines reject relative URLs, so the sitemap needs a base URL
+		if opts.BaseURL != "" {
+			if err := generateSitemap(outputDir, opts.BaseURL, allExercises, opts.URLs); err != nil {
+				return 0, fmt.Errorf("generating sitemap: %w", err)
+			}
+		} else {
+			fmt.Println("ℹ️  No -base-url given, skipping sitemap.xml")
+		}
+	}
+
+	if err := opts.cache.save(); err != nil {
+		return 0, err
+	}
+	if err := opts.playground.save(); err != nil {
+		return 0, err
+	}
+	// The service worker precaches everything generated, so it comes last
+	if opts.PWA && !opts.DryRun {
+		if err := generatePWA(outputDir, opts); err != nil {
+			return 0, fmt.Errorf("generating PWA files: %w", err)
+		}
+	}
+	// Only compressed once every file is final
+	var gzipped *gzipStats
+	if opts.Gzip && !opts.DryRun {
+		var err error
+		if gzipped, err = gzipOutput(outputDir); err != nil {
+			return 0, err
+		}
+	}
+
+	opts.Transforms.report()
+	opts.minifier.report()
+	opts.cache.report()
+	gzipped.report()
+
+	return totalPages, nil
+}
+
+// generateLanguageFiles writes the files of a language besides its pages:
+// the feed, all.html, the search index, the manifest, the tag pages and the
+// map.
+func generateLanguageFiles(outputDir string, lang LangConfig, exercises []Exercise, siteRoot string, opts buildOptions) error {
+	// Feed readers need absolute links, so like the sitemap the feed is
+	// only generated with a base URL
+	if opts.BaseURL != "" {
+		if err := generateFeed(outputDir, opts.BaseURL, lang, exercises, opts.URLs); err != nil {
+			return fmt.Errorf("generating feed (%s): %w", lang.Code, err)
+		}
+	}
+
+	if opts.SinglePage {
+		if err := generateSinglePage(outputDir, lang, exercises, siteRoot, opts); err != nil {
+			return fmt.Errorf("generating single page (%s): %w", lang.Code, err)
+		}
+	}
+
+	// Generate the client-side search index
+	if err := generateSearchIndex(outputDir, lang, exercises); err != nil {
+		return fmt.Errorf("generating search index (%s): %w", lang.Code, err)
+	}
+
+	if err := generateManifest(outputDir, exercises); err != nil {
+		return fmt.Errorf("generating manifest (%s): %w", lang.Code, err)
+	}
+	if err := generatePrerequisites(outputDir, exercises); err != nil {
+		return fmt.Errorf("generating prerequisites (%s): %w", lang.Code, err)
 	}
 
-	fmt.Println("✅ Website generated successfully!")
-	fmt.Printf("📁 Output directory: %s\n", *outputDir)
-	fmt.Printf("📄 Generated %d pages total (including all languages)\n", totalPages)
+	// Tag pages link back to the index, so they follow it
+	if err := generateTagPages(outputDir, lang, exercises, siteRoot, opts); err != nil {
+		return err
+	}
+
+	// Generate standalone workshop map
+	if err := generateMapFile(outputDir, lang, exercises); err != nil {
+		return fmt.Errorf("generating workshop map (%s): %w", lang.Code, err)
+	}
+	return nil
+}
+
+// loadExercises loads every exercise of a language, opts.Jobs at a time.
+// The result is in workshop order regardless of which finishes first. An
+// exercise that fails to load is recorded as a build error and left out,
+// so the rest of the site is still generated; it only returns an error
+// when no exercise could be loaded.
+func loadExercises(exercisesDir string, lang LangConfig, siteRoot, altLangURLPrefix string, opts buildOptions) ([]Exercise, error) {
+	exercises := make([]Exercise, len(lang.Metadata))
+	errs := make([]error, len(lang.Metadata))
+	var g errgroup.Group
+	g.SetLimit(opts.Jobs)
+	for i, meta := range lang.Metadata {
+		i, meta := i, meta
+		g.Go(func() error {
+			exercises[i], errs[i] = loadExercise(exercisesDir, lang, meta, i, siteRoot, altLangURLPrefix, opts)
+			return nil
+		})
+	}
+	g.Wait()
+
+	loaded := exercises[:0]
+	for i, exercise := range exercises {
+		if errs[i] != nil {
+			file := lang.sourceFile(lang.Metadata[i].Filename)
+			opts.diags.errorf(categoryBuild, file, "%s", strings.TrimPrefix(errs[i].Error(), file+": "))
+			continue
+		}
+		loaded = append(loaded, exercise)
+	}
+	if len(loaded) == 0 {
+		return nil, fmt.Errorf("no exercise could be generated for %s", lang.Code)
+	}
+	return loaded, nil
+}
+
+// generateExercisePages writes the page of every exercise, opts.Jobs at a
+// time. Each page goes to its own file, and pages that fail are recorded
+// as build errors without stopping the others.
+func generateExercisePages(outputDir string, lang LangConfig, exercises []Exercise, opts buildOptions) {
+	errs := make([]error, len(exercises))
+	var g errgroup.Group
+	g.SetLimit(opts.Jobs)
+	for i, exercise := range exercises {
+		i, exercise := i, exercise
+		g.Go(func() error {
+			errs[i] = generateExercisePage(outputDir, ExercisePageData{Exercise: exercise, All: exercises}, opts)
+			return nil
+		})
+	}
+	g.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			opts.diags.errorf(categoryBuild, lang.sourceFile(exercises[i].Name), "%v", err)
+		}
+	}
 }
 
-func generateExercisePage(exercisesDir, outputDir string, lang LangConfig, meta exerciseMeta, index int, cssPath, homePath, altLangURLPrefix string) (Exercise, error) {
+// loadExercise reads and renders an exercise, returning everything its page
+// needs except the list of other exercises.
+func loadExercise(exercisesDir string, lang LangConfig, meta exerciseMeta, index int, siteRoot, altLangURLPrefix string, opts buildOptions) (Exercise, error) {
 	// Read markdown file
-	mdFilename := meta.Filename + lang.FileSuffix
+	mdFilename := lang.sourceFile(meta.Filename)
 	mdPath := filepath.Join(exercisesDir, mdFilename)
 	content, err := os.ReadFile(mdPath)
 	if err != nil {
 		return Exercise{}, fmt.Errorf("reading markdown file: %w", err)
 	}
+	info, err := os.Stat(mdPath)
+	if err != nil {
+		return Exercise{}, fmt.Errorf("reading markdown file: %w", err)
+	}
+	lastUpdated := info.ModTime()
+	if committed, ok := opts.gitDates[filepath.ToSlash(mdFilename)]; ok {
+		lastUpdated = committed
+	}
 
-	// Convert markdown to HTML
-	htmlContent := markdownToHTML(content)
+	// Split off the front matter before anything looks at the body
+	fm, content, err := parseFrontmatter(content)
+	if err == nil {
+		err = fm.validate()
+	}
+	if err == nil {
+		content, err = expandIncludes(content, exercisesDir, mdFilename)
+	}
+	if err != nil {
+		return Exercise{}, fmt.Errorf("%s: %w", mdFilename, err)
+	}
+	if opts.Strict {
+		if len(fm.Objectives) == 0 {
+			return Exercise{}, fmt.Errorf("%s: front matter has no objectives", mdFilename)
+		}
+		if len(fm.Takeaways) == 0 {
+			return Exercise{}, fmt.Errorf("%s: front matter has no takeaways", mdFilename)
+		}
+	}
+	for _, name := range fm.Related {
+		switch {
+		case name == meta.Filename:
+			opts.diags.warnf(categoryLinks, mdFilename, "exercise lists itself as related")
+		case !slices.ContainsFunc(lang.Metadata, func(m exerciseMeta) bool { return m.Filename == name }):
+			opts.diags.warnf(categoryLinks, mdFilename, "related exercise %q not found", name)
+		}
+	}
+
+	// Front matter wins over the compiled-in metadata
+	if fm.Title != "" {
+		meta.Title = fm.Title
+	}
+	if fm.Description != "" {
+		meta.Description = fm.Description
+	}
+	emoji := meta.Emoji
+	if fm.Emoji != "" {
+		emoji = fm.Emoji
+	}
+	if emoji == "" {
+		emoji = pickEmoji(meta.Filename, meta.Title)
+	} else if !isSingleEmoji(emoji) {
+		report := opts.diags.warnf
+		if opts.Strict {
+			report = opts.diags.errorf
+		}
+		report(categoryBuild, mdFilename, "emoji %q is not a single emoji", emoji)
+	}
+
+	// Relative path from this page back to its language directory
+	homePath := opts.URLs.rootPrefix(meta.Filename)
+	homeURL := opts.URLs.link(meta.Filename, "index")
+
+	// Apply markdown-stage transforms, convert, then apply HTML-stage ones
+	markdown, err := opts.Transforms.apply(stageMarkdown, mdFilename, content)
+	if err != nil {
+		return Exercise{}, err
+	}
+	markdown, hasWasm, err := expandWasmDirectives(markdown, exercisesDir, homePath+siteRoot, opts.wasm)
+	if err != nil {
+		return Exercise{}, fmt.Errorf("%s: %w", mdFilename, err)
+	}
+	converted := renderMarkdownBody(markdown, markdownOptions{
+		GoVersion:   opts.GoVersion,
+		LineNumbers: opts.CodeLineNumbers,
+		playground:  opts.playground,
+	}, opts.Math)
+	rendered, err := opts.Transforms.apply(stageHTML, mdFilename, []byte(converted))
+	if err != nil {
+		return Exercise{}, err
+	}
+	// Referenced files are linked from the page, and from all.html in the
+	// language directory
+	pageHTML, err := opts.assets.rewrite(string(rendered), mdFilename, siteRoot)
+	if err != nil {
+		return Exercise{}, err
+	}
+	htmlContent, err := opts.assets.rewrite(string(rendered), mdFilename, homePath+siteRoot)
+	if err != nil {
+		return Exercise{}, err
+	}
+	htmlContent = opts.URLs.rewriteLinks(htmlContent, meta.Filename)
+	htmlContent, headings := anchorHeadings(htmlContent)
+	toc := buildTOC(headings, opts.TOCDepth)
 
 	// Generate HTML filename
-	htmlFilename := meta.Filename + ".html"
+	htmlFilename := opts.URLs.pageFile(meta.Filename)
 
 	// Determine prev/next links
-	prevLink := homePath + "index.html"
+	prevLink, prevTitle := homeURL, ""
 	if index > 0 {
-		prevLink = lang.Metadata[index-1].Filename + ".html"
+		prevLink = opts.URLs.link(meta.Filename, lang.Metadata[index-1].Filename)
+		prevTitle = lang.Metadata[index-1].Title
 	}
 
-	nextLink := ""
+	nextLink, nextTitle := "", ""
 	if index < len(lang.Metadata)-1 {
-		nextLink = lang.Metadata[index+1].Filename + ".html"
+		nextLink = opts.URLs.link(meta.Filename, lang.Metadata[index+1].Filename)
+		nextTitle = lang.Metadata[index+1].Title
 	}
 
 	// Alt language URL for the same exercise
-	altLangURL := altLangURLPrefix + htmlFilename
-
-	exercise := Exercise{
-		Number:      index,
-		Title:       meta.Title,
-		Description: meta.Description,
-		Filename:    htmlFilename,
-		Content:     template.HTML(htmlContent),
-		PrevLink:    prevLink,
-		NextLink:    nextLink,
-		Lang:        lang.Code,
-		AltLangURL:  altLangURL,
-		AltLangName: lang.AltLangName,
-		CSSPath:     cssPath,
-		HomePath:    homePath,
-	}
-
-	// Generate HTML page
-	tmpl, err := template.New("exercise").Funcs(template.FuncMap{
-		"add": func(a, b int) int {
-			return a + b
-		},
-	}).Parse(exerciseTemplate)
-	if err != nil {
-		return Exercise{}, fmt.Errorf("parsing template: %w", err)
+	altLangURL := ""
+	if altLangURLPrefix != "" {
+		altLangURL = homePath + altLangURLPrefix + opts.URLs.pageURL(meta.Filename)
 	}
 
-	outputPath := filepath.Join(outputDir, htmlFilename)
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return Exercise{}, fmt.Errorf("creating output file: %w", err)
+	// Only pages that walk through the Go tree get the version banner
+	goVersionNote := ""
+	if touchesGoSource(content) {
+		goVersionNote = fmt.Sprintf(lang.UIStrings.GoVersionBanner, opts.GoVersion)
 	}
-	defer f.Close()
+	words := wordCount(content)
 
-	if err := tmpl.Execute(f, exercise); err != nil {
-		return Exercise{}, fmt.Errorf("executing template: %w", err)
+	exercise := Exercise{
+		Number:           exerciseNumber(meta.Filename, index),
+		Index:            index,
+		Slug:             exerciseSlug(meta.Filename),
+		Name:             meta.Filename,
+		Group:            exerciseGroup(meta.Filename),
+		Title:            meta.Title,
+		Emoji:            emoji,
+		Description:      meta.Description,
+		ReadingTime:      readingTime(words),
+		WordCount:        words,
+		EstimatedMinutes: fm.EstimatedMinutes,
+		Authors:          fm.Authors,
+		EditURL:          editURL(opts.editBase, mdFilename),
+		SourceURL:        sourceURL(homePath, meta.Filename, opts),
+		Versions:         versionLinks(homePath+siteRoot, lang, meta.Filename, opts),
+		Difficulty:       fm.Difficulty,
+		Tags:             fm.Tags,
+		DifficultyName:   lang.UIStrings.Difficulties[fm.Difficulty],
+		Filename:         htmlFilename,
+		URL:              opts.URLs.pageURL(meta.Filename),
+		AbsoluteURL:      pageAbsoluteURL(opts.BaseURL, lang, opts.URLs.pageURL(meta.Filename)),
+		Content:          template.HTML(htmlContent),
+		TOC:              toc,
+		PrevLink:         prevLink,
+		NextLink:         nextLink,
+		PrevTitle:        prevTitle,
+		NextTitle:        nextTitle,
+		Lang:             lang.Code,
+		AltLangURL:       altLangURL,
+		AltLangName:      lang.AltLangName,
+		CSSPath:          homePath + siteRoot + "style.css",
+		SiteRoot:         homePath + siteRoot,
+		HomePath:         homePath,
+		HomeURL:          homeURL,
+		GoVersion:        opts.GoVersion,
+		GoVersionNote:    goVersionNote,
+		CopyFeedbackMs:   opts.CopyFeedback.Milliseconds(),
+		Environment:      opts.Environment,
+		Objectives:       fm.Objectives,
+		Takeaways:        fm.Takeaways,
+		Prerequisites:    fm.Prerequisites,
+		Related:          fm.Related,
+		HasWasm:          hasWasm,
+		HasMermaid:       strings.Contains(htmlContent, mermaidDiagramTag),
+		HasMath:          strings.Contains(htmlContent, mathClass),
+		Favicon:          faviconURL(homePath+siteRoot, opts),
+		PWA:              opts.PWA,
+		LastUpdated:      lastUpdated,
+		AssetBase:        assetBase(opts.Offline, homePath+siteRoot),
+		pageHTML:         pageHTML,
+		sourcePath:       mdPath,
 	}
-
-	fmt.Printf("✓ Generated %s [%s]\n", htmlFilename, lang.Code)
 	return exercise, nil
 }
 
-func generateIndexPage(outputDir string, lang LangConfig, exercises []Exercise, cssPath, homePath, altLangURLPrefix string) error {
-	tmpl, err := template.New("index").Funcs(template.FuncMap{
-		"safeHTML": func(s string) template.HTML {
-			return template.HTML(s)
-		},
-	}).Parse(indexTemplate)
+func generateExercisePage(outputDir string, page ExercisePageData, opts buildOptions) error {
+	outputPath := filepath.Join(outputDir, page.Filename)
+	structuredData, err := exerciseStructuredData(page.Exercise)
 	if err != nil {
-		return fmt.Errorf("parsing template: %w", err)
+		return err
+	}
+	page.StructuredData = structuredData
+	page.PrerequisiteExercises = findExercises(page.Prerequisites, page.Name, page.All)
+	page.RelatedExercises = findExercises(page.Related, page.Name, page.All)
+	page.Total = page.All[len(page.All)-1].Number
+	if page.Total > 0 {
+		page.Percent = page.Number * 100 / page.Total
+	}
+	unchanged, err := opts.cache.unchanged(outputPath, page)
+	if err != nil || unchanged {
+		return err
+	}
+	if !opts.DryRun {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+	}
+	if err := writePage(outputPath, page.AbsoluteURL, opts.Templates.exercise, page, opts); err != nil {
+		opts.cache.forget(outputPath)
+		return err
+	}
+	if page.SourceURL != "" {
+		if err := copySource(outputDir, page.Exercise, opts.DryRun); err != nil {
+			opts.cache.forget(outputPath)
+			return err
+		}
 	}
 
-	outputPath := filepath.Join(outputDir, "index.html")
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("creating output file: %w", err)
+	if !opts.DryRun {
+		fmt.Printf("✓ Generated %s [%s]\n", page.Filename, page.Lang)
 	}
-	defer f.Close()
+	return nil
+}
+
+func generateIndexPage(outputDir string, lang LangConfig, exercises []Exercise, siteRoot, altLangURLPrefix string, opts buildOptions) error {
+	outputPath := filepath.Join(outputDir, "index.html")
 
 	// Format overview text with exercise count
 	ui := lang.UIStrings
 	ui.OverviewText = fmt.Sprintf(ui.OverviewText, len(exercises))
+	ui.HeroVersionNote = fmt.Sprintf(ui.HeroVersionNote, opts.GoVersion)
+	// Exercises without an estimate are left out of the total
+	estimated := 0
+	for _, ex := range exercises {
+		estimated += ex.EstimatedMinutes
+	}
+	if estimated > 0 {
+		ui.EstimatedTotal = fmt.Sprintf(ui.EstimatedTotal, formatMinutes(estimated))
+	} else {
+		ui.EstimatedTotal = ""
+	}
 
 	// Format getting started items with link prefix
 	formattedGSItems := make([]string, len(ui.GettingStartedItems))
 	for i, item := range ui.GettingStartedItems {
 		if strings.Contains(item, "%s") {
-			formattedGSItems[i] = fmt.Sprintf(item, "")
+			formattedGSItems[i] = fmt.Sprintf(item, opts.URLs.pageURL(lang.Metadata[0].Filename))
 		} else {
 			formattedGSItems[i] = item
 		}
 	}
 	ui.GettingStartedItems = formattedGSItems
 
+	structuredData, err := indexStructuredData(ui.HeroTitle, exercises)
+	if err != nil {
+		return err
+	}
+
+	altLangURL := ""
+	if altLangURLPrefix != "" {
+		altLangURL = altLangURLPrefix + opts.URLs.pageURL("index")
+	}
+
+	searchIndexURL := "search-index.json"
+	if opts.BaseURL != "" {
+		searchIndexURL = absoluteURL(opts.BaseURL, lang, searchIndexURL)
+	}
+
 	data := struct {
 		IndexData
 		UI              UIStrings
 		AltLangURLIndex string
+		HomeURL         string
+		StartURL        string
+		WorkshopMap     template.HTML
+		HasFeed         bool
+		Tags            []string
+		TagPages        map[string]string
+		Contributors    []string
+		Versions        []versionLink
+		PerPage         int
+		AssetBase       string
+		Groups          []exerciseGroupCards
+		AbsoluteURL     string
+		SearchIndexURL  string
+		StructuredData  template.JS
+		GoVersion       string
+		Favicon         template.URL
+		PWA             bool
+		SiteRoot        string
+		CopyFeedbackMs  int64
+		Environment     string
 	}{
 		IndexData: IndexData{
 			Exercises:   exercises,
 			Lang:        lang.Code,
-			AltLangURL:  altLangURLPrefix + "index.html",
+			AltLangURL:  altLangURL,
 			AltLangName: lang.AltLangName,
-			CSSPath:     cssPath,
-			HomePath:    homePath,
+			CSSPath:     siteRoot + "style.css",
 		},
 		UI:              ui,
-		AltLangURLIndex: altLangURLPrefix + "index.html",
+		AltLangURLIndex: altLangURL,
+		HomeURL:         opts.URLs.pageURL("index"),
+		StartURL:        opts.URLs.pageURL(lang.Metadata[0].Filename),
+		WorkshopMap:     template.HTML(workshopMap(exercises, ui.Exercise)),
+		HasFeed:         opts.BaseURL != "",
+		Tags:            exerciseTags(exercises),
+		TagPages:        tagPageFiles(exerciseTags(exercises)),
+		Contributors:    exerciseAuthors(exercises),
+		Versions:        versionLinks(siteRoot, lang, "", opts),
+		PerPage:         opts.PerPage,
+		AssetBase:       assetBase(opts.Offline, siteRoot),
+		CopyFeedbackMs:  opts.CopyFeedback.Milliseconds(),
+		Environment:     opts.Environment,
+		Groups:          groupExercises(exercises, opts.GroupByDir),
+		AbsoluteURL:     pageAbsoluteURL(opts.BaseURL, lang, opts.URLs.pageURL("index")),
+		SearchIndexURL:  searchIndexURL,
+		StructuredData:  structuredData,
+		GoVersion:       opts.GoVersion,
+		Favicon:         faviconURL(siteRoot, opts),
+		PWA:             opts.PWA,
+		SiteRoot:        siteRoot,
 	}
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("executing template: %w", err)
+	if err := writePage(outputPath, data.AbsoluteURL, opts.Templates.index, data, opts); err != nil {
+		return err
 	}
 
-	fmt.Printf("✓ Generated index.html [%s]\n", lang.Code)
+	if !opts.DryRun {
+		fmt.Printf("✓ Generated index.html [%s]\n", lang.Code)
+	}
 	return nil
 }
 
-func copyCSSFile(outputDir string) error {
-	cssContent := cssTemplate
+// exerciseSlug strips the numeric ordering prefix from an exercise filename,
+// e.g. "02-scanner-arrow-operator" becomes "scanner-arrow-operator".
+func exerciseSlug(filename string) string {
+	filename = path.Base(filename)
+	if prefix, rest, ok := strings.Cut(filename, "-"); ok && strings.Trim(prefix, "0123456789") == "" {
+		return rest
+	}
+	return filename
+}
+
+// exerciseNumber returns the number shown for an exercise: the numeric
+// prefix of its filename, e.g. 2 for "02-scanner-arrow-operator", or index
+// when there is none.
+func exerciseNumber(filename string, index int) int {
+	if prefix, _, ok := strings.Cut(path.Base(filename), "-"); ok {
+		if n, err := strconv.Atoi(prefix); err == nil {
+			return n
+		}
+	}
+	return index
+}
+
+// exerciseGroupCards is a titled set of cards on the index page.
+type exerciseGroupCards struct {
+	Title     string
+	Exercises []Exercise
+}
+
+// groupExercises splits the exercises by top-level directory, in order of
+// first appearance, or returns a single untitled group when byDir is off.
+// Exercises at the root of the exercises directory form an untitled group.
+func groupExercises(exercises []Exercise, byDir bool) []exerciseGroupCards {
+	if !byDir {
+		return []exerciseGroupCards{{Exercises: exercises}}
+	}
+	var groups []exerciseGroupCards
+	index := make(map[string]int)
+	for _, ex := range exercises {
+		i, ok := index[ex.Group]
+		if !ok {
+			i = len(groups)
+			index[ex.Group] = i
+			title := ""
+			if ex.Group != "" {
+				title = humanizeExerciseName(ex.Group)
+			}
+			groups = append(groups, exerciseGroupCards{Title: title})
+		}
+		groups[i].Exercises = append(groups[i].Exercises, ex)
+	}
+	return groups
+}
+
+// exerciseGroup returns the top-level directory of an exercise, or "" for
+// exercises at the root of the exercises directory.
+func exerciseGroup(filename string) string {
+	if dir, _, ok := strings.Cut(filename, "/"); ok {
+		return dir
+	}
+	return ""
+}
+
+// substitute replaces goVersionToken in text.
+func (o markdownOptions) substitute(text []byte) []byte {
+	if o.GoVersion == "" {
+		return text
+	}
+	return bytes.ReplaceAll(text, []byte(goVersionToken), []byte(o.GoVersion))
+}
+
+// findExercises looks up the named exercises in all, in the order given,
+// for the exercise called self. Names that match no exercise of the
+// language, or self, were reported when loading and are skipped.
+func findExercises(names []string, self string, all []Exercise) []Exercise {
+	var found []Exercise
+	for _, name := range names {
+		i := slices.IndexFunc(all, func(other Exercise) bool { return other.Name == name })
+		if i >= 0 && name != self {
+			found = append(found, all[i])
+		}
+	}
+	return found
+}
+
+// exerciseTags returns every tag used by the exercises, sorted.
+func exerciseTags(exercises []Exercise) []string {
+	var tags []string
+	for _, ex := range exercises {
+		for _, tag := range ex.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// exerciseAuthors returns every author of the exercises once, in the order
+// they first appear in the workshop.
+func exerciseAuthors(exercises []Exercise) []string {
+	var authors []string
+	for _, ex := range exercises {
+		for _, author := range ex.Authors {
+			if !slices.Contains(authors, author) {
+				authors = append(authors, author)
+			}
+		}
+	}
+	return authors
+}
+
+// readingWordsPerMinute is the reading speed used for the reading-time
+// estimate.
+const readingWordsPerMinute = 200
+
+// Markup wordCount leaves out of the text.
+var (
+	// wordCountHTMLRe matches HTML tags and single-line comments
+	wordCountHTMLRe = regexp.MustCompile(`<!--.*?-->|</?[A-Za-z][^>]*>`)
+	// wordCountCodeRe matches a code span, which reads as a single word
+	wordCountCodeRe = regexp.MustCompile("(`+)[^`]+?(`+)")
+	// wordCountLinkRe matches the target of a link or image, leaving its
+	// text
+	wordCountLinkRe = regexp.MustCompile(`\]\([^)]*\)`)
+)
+
+// wordCount counts the words of an exercise's markdown as a reader sees
+// them. Fenced code blocks and HTML are left out, a code span counts as
+// one word, hyphenated words count once, and markup such as list bullets
+// or link targets doesn't count.
+func wordCount(markdown []byte) int {
+	words := 0
+	fence := ""
+	for _, line := range strings.Split(string(markdown), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			fence = trimmed[:3]
+		case fence != "" && strings.HasPrefix(trimmed, fence):
+			fence = ""
+		case fence == "":
+			line = wordCountCodeRe.ReplaceAllString(line, " code ")
+			line = wordCountLinkRe.ReplaceAllString(line, "] ")
+			line = wordCountHTMLRe.ReplaceAllString(line, " ")
+			for _, field := range strings.Fields(line) {
+				if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+					words++
+				}
+			}
+		}
+	}
+	return words
+}
+
+// readingTime estimates the minutes needed to read words, the wordCount of
+// an exercise, rounded up to at least one minute. Fenced code blocks are
+// not counted, so code-heavy pages aren't overestimated.
+func readingTime(words int) int {
+	return max(1, (words+readingWordsPerMinute-1)/readingWordsPerMinute)
+}
+
+// formatMinutes spells out a duration in minutes, e.g. "45 min" or
+// "6 h 30 min".
+func formatMinutes(minutes int) string {
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%d min", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d h", minutes/60)
+	default:
+		return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+	}
+}
+
+// touchesGoSource reports whether an exercise refers to files inside the
+// Go source tree, where line numbers depend on the checked out release.
+func touchesGoSource(markdown []byte) bool {
+	return strings.Contains(string(markdown), "go/src/")
+}
+
+// writePage executes tmpl with data and writes the result to path,
+// minified with -minify. Under -base-url, pageURL is the page's absolute
+// URL and its relative links are resolved against it.
+func writePage(path, pageURL string, tmpl *template.Template, data any, opts buildOptions) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	content, err := opts.minifier.minify("text/html", absoluteLinks(buf.Bytes(), pageURL))
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(path, content, opts.DryRun); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
+	}
+	return nil
+}
+
+func copyCSSFile(outputDir, css string, opts buildOptions) error {
 	outputPath := filepath.Join(outputDir, "style.css")
 
-	if err := os.WriteFile(outputPath, []byte(cssContent), 0o644); err != nil {
+	content, err := opts.minifier.minify("text/css", []byte(css))
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(outputPath, content, opts.DryRun); err != nil {
 		return fmt.Errorf("writing CSS file: %w", err)
 	}
 
-	fmt.Printf("✓ Generated style.css\n")
+	if !opts.DryRun {
+		fmt.Printf("✓ Generated style.css\n")
+	}
 	return nil
 }
 
-func markdownToHTML(markdown []byte) string {
-	// Use blackfriday to convert markdown to HTML
-	renderer := blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
-		Flags: blackfriday.CommonHTMLFlags,
-	})
+// writeOutput writes content to path, or with -dry-run only logs what
+// would be written.
+func writeOutput(path string, content []byte, dryRun bool) error {
+	if dryRun {
+		fmt.Printf("📝 Would write %s (%d bytes)\n", path, len(content))
+		return nil
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// goVersionToken is replaced with the -go-version value in exercise text
+// and links, and in code blocks tagged "substitute".
+const goVersionToken = "${GO_VERSION}"
+
+// markdownOptions adjusts how markdownToHTML renders exercises.
+type markdownOptions struct {
+	// GoVersion replaces goVersionToken; without it the token is kept
+	GoVersion string
+	// LineNumbers numbers the lines of highlighted code blocks
+	LineNumbers bool
+	// playground, if set, adds Run links to complete Go programs
+	playground *playgroundLinks
+}
+
+// renderMarkdownBody converts the body of an exercise, its front matter
+// already split off, to HTML: collapsible sections are expanded and, with
+// math, formulas are kept away from the markdown parser.
+func renderMarkdownBody(markdown []byte, mdOpts markdownOptions, math bool) string {
+	markdown = expandCollapsibles(markdown)
+	var formulas []mathSpan
+	if math {
+		markdown, formulas = extractMath(markdown)
+	}
+	return restoreMath(markdownToHTML(markdown, mdOpts), formulas)
+}
+
+func markdownToHTML(markdown []byte, mdOpts markdownOptions) string {
+	// Use blackfriday to convert markdown to HTML, highlighting code blocks
+	// and fixing relative links
+	renderer := markdownRenderer{
+		HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+			Flags: blackfriday.CommonHTMLFlags,
+		}),
+		admonitions:     make(map[*blackfriday.Node]bool),
+		markdownOptions: mdOpts,
+	}
 
 	// Process the markdown
 	html := blackfriday.Run(markdown, blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	return string(html)
+}
 
-	// Post-process to fix relative links
-	htmlStr := string(html)
-	htmlStr = fixRelativeLinks(htmlStr)
-
-	return htmlStr
+// mermaidDiagramTag opens a ```mermaid block, which is rendered in the
+// browser by the Mermaid library instead of being highlighted.
+const mermaidDiagramTag = `<div class="mermaid">`
+
+// markdownRenderer is blackfriday's HTML renderer with fenced code blocks
+// highlighted at build time and captioned by their title="...", Mermaid
+// diagrams and formulas passed through, "> [!NOTE]" style blockquotes
+// turned into callouts, "- [ ]" items turned into checkboxes, <details>
+// sections kept out of paragraphs, :emoji: shortcodes replaced and links
+// to markdown files pointed at the generated pages. Only link destinations
+// are rewritten, so code and text mentioning .md files are left alone.
+type markdownRenderer struct {
+	*blackfriday.HTMLRenderer
+	// admonitions holds the blockquotes rendered as callouts, to close
+	// them with a </div>
+	admonitions map[*blackfriday.Node]bool
+	markdownOptions
 }
 
-func fixRelativeLinks(html string) string {
-	// Convert markdown links to HTML links
-	re := regexp.MustCompile(`href="\.\./(README\.md|exercises/([^"]+)\.md)"`)
-	html = re.ReplaceAllStringFunc(html, func(match string) string {
-		if strings.Contains(match, "README.md") {
-			return `href="index.html"`
+func (r markdownRenderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	switch node.Type {
+	case blackfriday.CodeBlock:
+		if codeBlockFlag(node, "substitute") {
+			node.Literal = r.substitute(node.Literal)
 		}
-		// Extract filename from exercises/XX-name.md
-		re2 := regexp.MustCompile(`exercises/([^"]+)\.md`)
-		matches := re2.FindStringSubmatch(match)
-		if len(matches) > 1 {
-			return fmt.Sprintf(`href="%s.html"`, matches[1])
+		if codeBlockLang(node) == "mermaid" {
+			// Mermaid reads the diagram source from the element's text
+			io.WriteString(w, mermaidDiagramTag+html.EscapeString(string(node.Literal))+"</div>\n")
+			return blackfriday.GoToNext
 		}
-		return match
-	})
+		if codeBlockLang(node) == "math" {
+			io.WriteString(w, `<div class="math math-display">`+html.EscapeString(string(node.Literal))+"</div>\n")
+			return blackfriday.GoToNext
+		}
+		// The caption bar sits right above the code
+		if title := codeBlockTitle(node); title != "" {
+			fmt.Fprintf(w, "<div class=\"code-filename\">%s</div>\n", html.EscapeString(title))
+		}
+		if renderCodeBlock(w, node, r.LineNumbers) {
+			r.playground.writeRunLink(w, node)
+			return blackfriday.GoToNext
+		}
+	case blackfriday.BlockQuote:
+		if entering {
+			if a, ok := parseAdmonition(node); ok {
+				r.admonitions[node] = true
+				writeAdmonitionStart(w, a)
+				return blackfriday.GoToNext
+			}
+		} else if r.admonitions[node] {
+			io.WriteString(w, "</div>\n")
+			return blackfriday.GoToNext
+		}
+	case blackfriday.Paragraph:
+		// Raw <details> tags stand on their own, not in a <p>
+		if isDetailsParagraph(node) {
+			if !entering {
+				io.WriteString(w, "\n")
+			}
+			return blackfriday.GoToNext
+		}
+	case blackfriday.Item:
+		if entering && parseTaskItem(node) {
+			r.writeTaskItemStart(w, node)
+			return blackfriday.GoToNext
+		}
+	case blackfriday.Text:
+		// Code spans and blocks are nodes of their own, so their
+		// shortcodes and version tokens are kept
+		node.Literal = replaceEmojiShortcodes(r.substitute(node.Literal))
+	case blackfriday.Link:
+		if entering {
+			node.LinkData.Destination = []byte(fixRelativeLink(string(r.substitute(node.LinkData.Destination))))
+		}
+	}
+	return r.HTMLRenderer.RenderNode(w, node, entering)
+}
+
+// Markdown link targets rewritten by fixRelativeLink, matched against the
+// path alone. The .md or .markdown extension may be in any case.
+var (
+	// readmeLinkRe matches the repository README, which becomes the index
+	readmeLinkRe = regexp.MustCompile(`^(?:\.\./)+README\.(?i:md|markdown)$`)
+	// exercisesDirLinkRe matches exercises/XX-name.md reached from outside
+	// the exercises directory
+	exercisesDirLinkRe = regexp.MustCompile(`^(?:\.\./)+exercises/(.+)\.(?i:md|markdown)$`)
+	// exerciseLinkRe matches XX-name.md or XX-name.es.md, keeping any
+	// relative directory for exercises in subdirectories
+	exerciseLinkRe = regexp.MustCompile(`^(?:\./)?((?:[^:/]+/)*)([0-9]{2}-[^/]+?)(?:\.es)?\.(?i:md|markdown)$`)
+)
+
+// fixRelativeLink points a link to a markdown file at the generated page.
+// Only the path is rewritten; a query or fragment is kept as written.
+func fixRelativeLink(dest string) string {
+	target, suffix := dest, ""
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		target, suffix = dest[:i], dest[i:]
+	}
 
-	// Fix links that are already in the format XX-name.md or XX-name.es.md
-	re = regexp.MustCompile(`href="(?:\./)?([0-9]{2}-[^"]+?)(?:\.es)?\.md"`)
-	html = re.ReplaceAllString(html, `href="$1.html"`)
-
-	return html
-}
-
-const exerciseTemplate = `<!DOCTYPE html>
-<html lang="{{.Lang}}">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Exercise {{.Number}}: {{.Title}} - Go Source Code Workshop</title>
-    <link rel="stylesheet" href="{{.CSSPath}}">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/atom-one-dark.min.css">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.5.1/css/all.min.css">
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/highlight.min.js"></script>
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/languages/go.min.js"></script>
-    <script>
-        document.addEventListener('DOMContentLoaded', function() {
-            hljs.highlightAll();
-
-            // Add copy buttons to all code blocks
-            document.querySelectorAll('pre').forEach(function(pre) {
-                const button = document.createElement('button');
-                button.className = 'copy-button';
-                button.innerHTML = '<i class="far fa-copy"></i>';
-                button.title = 'Copy to clipboard';
-
-                button.addEventListener('click', function() {
-                    const code = pre.querySelector('code');
-                    const text = code.textContent;
-
-                    navigator.clipboard.writeText(text).then(function() {
-                        button.innerHTML = '<i class="fas fa-check"></i>';
-                        button.classList.add('copied');
-                        setTimeout(function() {
-                            button.innerHTML = '<i class="far fa-copy"></i>';
-                            button.classList.remove('copied');
-                        }, 2000);
-                    }).catch(function(err) {
-                        console.error('Failed to copy:', err);
-                    });
-                });
-
-                pre.appendChild(button);
-            });
-        });
-    </script>
-</head>
-<body>
-    <nav class="navbar">
-        <div class="container">
-            <a href="{{.HomePath}}index.html" class="nav-home">Having fun with the Go Source Code</a>
-            <div class="nav-links">
-                <a href="{{.HomePath}}index.html">{{if eq .Lang "es"}}Inicio{{else}}Home{{end}}</a>
-                <a href="{{.AltLangURL}}" class="lang-switch"><i class="fas fa-globe"></i> {{.AltLangName}}</a>
-                <a href="https://github.com/jespino/having-fun-with-the-go-source-code-workshop" target="_blank"><i class="fab fa-github"></i> Repository</a>
-            </div>
-        </div>
-    </nav>
-
-    <div class="container">
-        <article class="exercise-content">
-            {{.Content}}
-        </article>
-
-        <nav class="exercise-nav">
-            {{if .PrevLink}}
-            <a href="{{.PrevLink}}" class="nav-button">{{ if eq .PrevLink "index.html" }}{{if eq .Lang "es"}}← Inicio{{else}}← Home{{end}}{{ else }}{{if eq .Lang "es"}}← Anterior{{else}}← Previous{{end}}{{ end }}</a>
-            {{end}}
-            {{if .NextLink}}
-            <a href="{{.NextLink}}" class="nav-button">{{if eq .Lang "es"}}Siguiente{{else}}Next{{end}}: {{if eq .Lang "es"}}Ejercicio{{else}}Exercise{{end}} {{add .Number 1}} →</a>
-            {{end}}
-        </nav>
-    </div>
-
-    <footer>
-        <div class="container">
-            <p>Having fun with the Go Source Code</p>
-            <p>{{if eq .Lang "es"}}Creado por{{else}}Created by{{end}} <strong>Jesús Espino</strong></p>
-            <div class="footer-links">
-                <a href="https://github.com/jespino" target="_blank"><i class="fab fa-github"></i> GitHub</a>
-                <a href="https://x.com/jespinog" target="_blank"><i class="fab fa-x-twitter"></i> @jespinog</a>
-                <a href="https://linkedin.com/in/jesus-espino" target="_blank"><i class="fab fa-linkedin"></i> LinkedIn</a>
-            </div>
-        </div>
-    </footer>
-</body>
-</html>
-`
-
-const indexTemplate = `<!DOCTYPE html>
-<html lang="{{.Lang}}">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.UI.HeroTitle}}</title>
-    <link rel="stylesheet" href="{{.CSSPath}}">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/styles/atom-one-dark.min.css">
-    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.5.1/css/all.min.css">
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/highlight.min.js"></script>
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.9.0/languages/go.min.js"></script>
-    <script>
-        document.addEventListener('DOMContentLoaded', function() {
-            hljs.highlightAll();
-
-            // Add copy buttons to all code blocks
-            document.querySelectorAll('pre').forEach(function(pre) {
-                const button = document.createElement('button');
-                button.className = 'copy-button';
-                button.innerHTML = '<i class="far fa-copy"></i>';
-                button.title = 'Copy to clipboard';
-
-                button.addEventListener('click', function() {
-                    const code = pre.querySelector('code');
-                    const text = code.textContent;
-
-                    navigator.clipboard.writeText(text).then(function() {
-                        button.innerHTML = '<i class="fas fa-check"></i>';
-                        button.classList.add('copied');
-                        setTimeout(function() {
-                            button.innerHTML = '<i class="far fa-copy"></i>';
-                            button.classList.remove('copied');
-                        }, 2000);
-                    }).catch(function(err) {
-                        console.error('Failed to copy:', err);
-                    });
-                });
-
-                pre.appendChild(button);
-            });
-        });
-    </script>
-</head>
-<body>
-    <nav class="navbar">
-        <div class="container">
-            <a href="{{.HomePath}}index.html" class="nav-home">Having fun with the Go Source Code</a>
-            <div class="nav-links">
-                <a href="{{.HomePath}}index.html">{{.UI.Home}}</a>
-                <a href="{{.AltLangURL}}" class="lang-switch"><i class="fas fa-globe"></i> {{.AltLangName}}</a>
-                <a href="https://github.com/jespino/having-fun-with-the-go-source-code-workshop" target="_blank"><i class="fab fa-github"></i> Repository</a>
-            </div>
-        </div>
-    </nav>
-
-    <div class="container">
-        <header class="hero">
-            <h1>{{.UI.HeroTitle}}</h1>
-            <p class="lead">{{.UI.HeroLead}}</p>
-            <p class="version-note">{{safeHTML .UI.HeroVersionNote}}</p>
-        </header>
-
-        <section class="prerequisites">
-            <h2>{{.UI.Prerequisites}}</h2>
-            <ul>
-                {{range .UI.PrereqItems}}<li>{{safeHTML .}}</li>
-                {{end}}
-            </ul>
-        </section>
-
-        <section class="overview">
-            <h2>{{.UI.Overview}}</h2>
-            <p>{{safeHTML .UI.OverviewText}}</p>
-
-            <div class="exercises-grid">
-                {{range .Exercises}}
-                <a href="{{.Filename}}" class="exercise-card-link">
-                    <div class="exercise-card">
-                        <div class="exercise-number">{{if eq .Lang "es"}}Ejercicio{{else}}Exercise{{end}} {{.Number}}</div>
-                        <h3>{{.Title}}</h3>
-                        <p>{{.Description}}</p>
-                    </div>
-                </a>
-                {{end}}
-            </div>
-        </section>
-
-        <section class="getting-started">
-            <h2>{{.UI.GettingStarted}}</h2>
-            <ol>
-                {{range .UI.GettingStartedItems}}<li>{{safeHTML .}}</li>
-                {{end}}
-            </ol>
-        </section>
-
-        <section class="tips">
-            <h2>{{.UI.Tips}}</h2>
-            <ul>
-                {{range .UI.TipItems}}<li>{{safeHTML .}}</li>
-                {{end}}
-            </ul>
-        </section>
-
-        <section class="resources">
-            <h2>{{.UI.Resources}}</h2>
-            <ul>
-                <li><a href="https://github.com/golang/go/tree/master/src/cmd/compile">Go Compiler Overview</a></li>
-                <li><a href="https://go.dev/ref/spec">Go Language Specification</a></li>
-                <li><a href="https://pkg.go.dev/runtime">Go Runtime Documentation</a></li>
-            </ul>
-
-            <h3>{{.UI.VideoReferences}}</h3>
-            <p>{{.UI.VideoRefsIntro}}</p>
-            <div class="video-grid">
-                <div class="video-container">
-                    <h4>{{.UI.VideoCompiler}}</h4>
-                    <iframe src="https://www.youtube.com/embed/qnmoAA0WRgE" frameborder="0" allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe>
-                    <p>{{.UI.VideoCompilerDesc}}</p>
-                </div>
-                <div class="video-container">
-                    <h4>{{.UI.VideoRuntime}}</h4>
-                    <iframe src="https://www.youtube.com/embed/YpRNFNFaLGY" frameborder="0" allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe>
-                    <p>{{.UI.VideoRuntimeDesc}}</p>
-                </div>
-            </div>
-        </section>
-
-        <section class="completion">
-            <h2>{{.UI.Completion}}</h2>
-            <p>{{.UI.CompletionIntro}}</p>
-            <ul>
-                {{range .UI.CompletionItems}}<li>{{safeHTML .}}</li>
-                {{end}}
-            </ul>
-
-            <p>{{safeHTML .UI.CompletionCongrats}}</p>
-            <ul>
-                {{range .UI.CompletionEnables}}<li>{{.}}</li>
-                {{end}}
-            </ul>
-        </section>
-
-        <section class="contributing">
-            <h2>{{.UI.Contributing}}</h2>
-            <p>{{safeHTML .UI.ContributingText}}</p>
-        </section>
-
-        <div class="cta">
-            <a href="00-introduction-setup.html" class="cta-button">{{.UI.CTAButton}}</a>
-        </div>
-    </div>
-
-    <footer>
-        <div class="container">
-            <p>{{.UI.FooterTitle}}</p>
-            <p>{{safeHTML .UI.FooterCreatedBy}}</p>
-            <div class="footer-links">
-                <a href="https://github.com/jespino" target="_blank"><i class="fab fa-github"></i> GitHub</a>
-                <a href="https://x.com/jespinog" target="_blank"><i class="fab fa-x-twitter"></i> @jespinog</a>
-                <a href="https://linkedin.com/in/jesus-espino" target="_blank"><i class="fab fa-linkedin"></i> LinkedIn</a>
-            </div>
-        </div>
-    </footer>
-</body>
-</html>
-`
+	switch {
+	case readmeLinkRe.MatchString(target):
+		target = "index.html"
+	case exercisesDirLinkRe.MatchString(target):
+		target = exercisesDirLinkRe.ReplaceAllString(target, "$1.html")
+	case exerciseLinkRe.MatchString(target):
+		target = exerciseLinkRe.ReplaceAllString(target, "$1$2.html")
+	default:
+		return dest
+	}
+	return target + suffix
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manifestEntry is one exercise in exercises.json. Prev and Next are page
+// URLs relative to the language directory, empty at either end.
+type manifestEntry struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Emoji       string `json:"emoji,omitempty"`
+	Description string `json:"description"`
+	Filename    string `json:"filename"`
+	URL         string `json:"url"`
+	Prev        string `json:"prev,omitempty"`
+	Next        string `json:"next,omitempty"`
+}
+
+// generateManifest writes exercises.json for a language, next to its index
+// page, describing the exercises and the order they link to each other in.
+// Unlike the search index it is indented so changes diff cleanly.
+func generateManifest(outputDir string, exercises []Exercise) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifestEntries(exercises)); err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "exercises.json"), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	fmt.Printf("✓ Generated exercises.json [%s]\n", exercises[0].Lang)
+	return nil
+}
+
+// manifestEntries describes exercises, in order, by their metadata.
+func manifestEntries(exercises []Exercise) []manifestEntry {
+	entries := make([]manifestEntry, len(exercises))
+	for i, ex := range exercises {
+		entries[i] = manifestEntry{
+			Number:      ex.Number,
+			Title:       ex.Title,
+			Emoji:       ex.Emoji,
+			Description: ex.Description,
+			Filename:    ex.Filename,
+			URL:         ex.URL,
+		}
+		if i > 0 {
+			entries[i].Prev = exercises[i-1].URL
+		}
+		if i < len(exercises)-1 {
+			entries[i].Next = exercises[i+1].URL
+		}
+	}
+	return entries
+}
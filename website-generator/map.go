@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Workshop map layout, in SVG user units.
+const (
+	mapColumns    = 4
+	mapNodeWidth  = 220
+	mapNodeHeight = 72
+	mapGapX       = 48
+	mapGapY       = 56
+	mapPadding    = 24
+	mapTitleRunes = 26
+)
+
+// workshopMap renders a flowchart SVG with one node per exercise, laid out
+// in a serpentine grid so consecutive exercises stay next to each other.
+// Each node links to its exercise page, and arrows lead to each exercise
+// from its prerequisites.
+func workshopMap(exercises []Exercise, exerciseLabel string) string {
+	rows := (len(exercises) + mapColumns - 1) / mapColumns
+	width := 2*mapPadding + mapColumns*mapNodeWidth + (mapColumns-1)*mapGapX
+	height := 2*mapPadding + rows*mapNodeHeight + max(rows-1, 0)*mapGapY
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="workshop-map" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="Workshop map">`, width, height, width, height)
+	b.WriteString(`<defs><marker id="map-arrow" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="#5DC9E2"/></marker></defs>`)
+
+	// Edges first so nodes are drawn on top of them
+	for _, edge := range mapEdges(exercises) {
+		x1, y1, x2, y2 := mapEdge(edge[0], edge[1])
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#5DC9E2" stroke-width="2" marker-end="url(#map-arrow)"/>`, x1, y1, x2, y2)
+	}
+
+	for i, ex := range exercises {
+		x, y := mapNodePosition(i)
+		fmt.Fprintf(&b, `<a href="%s">`, template.HTMLEscapeString(ex.URL))
+		fmt.Fprintf(&b, `<title>%s</title>`, template.HTMLEscapeString(ex.Title))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="10" fill="#ffffff" stroke="#00ADD8" stroke-width="2"/>`, x, y, mapNodeWidth, mapNodeHeight)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="sans-serif" font-size="12" font-weight="600" fill="#00ADD8">%s %d</text>`, x+14, y+26, template.HTMLEscapeString(exerciseLabel), ex.Number)
+		title := shortTitle(ex.Title)
+		if ex.Emoji != "" {
+			title = ex.Emoji + " " + title
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="sans-serif" font-size="14" fill="#2c3e50">%s</text>`, x+14, y+50, template.HTMLEscapeString(title))
+		b.WriteString(`</a>`)
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// mapEdges returns the arrows of the map as pairs of exercise indexes, from
+// each prerequisite to the exercise needing it. An exercise without
+// prerequisites follows the one before it, as the workshop runs in order.
+// Prerequisites that name no exercise are left out; checkPrerequisites
+// reports them.
+func mapEdges(exercises []Exercise) [][2]int {
+	index := make(map[string]int, len(exercises))
+	for i, ex := range exercises {
+		index[ex.Name] = i
+	}
+	var edges [][2]int
+	for i, ex := range exercises {
+		if len(ex.Prerequisites) == 0 {
+			if i > 0 {
+				edges = append(edges, [2]int{i - 1, i})
+			}
+			continue
+		}
+		for _, name := range ex.Prerequisites {
+			if from, ok := index[name]; ok {
+				edges = append(edges, [2]int{from, i})
+			}
+		}
+	}
+	return edges
+}
+
+// mapNodePosition returns the top-left corner of the node at index i.
+// Odd rows run right to left.
+func mapNodePosition(i int) (int, int) {
+	row, col := i/mapColumns, i%mapColumns
+	if row%2 == 1 {
+		col = mapColumns - 1 - col
+	}
+	x := mapPadding + col*(mapNodeWidth+mapGapX)
+	y := mapPadding + row*(mapNodeHeight+mapGapY)
+	return x, y
+}
+
+// mapEdge returns the endpoints of an arrow between two nodes, leaving
+// from the side of the source that faces the target.
+func mapEdge(from, to int) (x1, y1, x2, y2 int) {
+	fx, fy := mapNodePosition(from)
+	tx, ty := mapNodePosition(to)
+	switch {
+	case ty > fy:
+		return fx + mapNodeWidth/2, fy + mapNodeHeight, tx + mapNodeWidth/2, ty
+	case ty < fy:
+		return fx + mapNodeWidth/2, fy, tx + mapNodeWidth/2, ty + mapNodeHeight
+	case tx > fx:
+		return fx + mapNodeWidth, fy + mapNodeHeight/2, tx, ty + mapNodeHeight/2
+	default:
+		return fx, fy + mapNodeHeight/2, tx + mapNodeWidth, ty + mapNodeHeight/2
+	}
+}
+
+// shortTitle keeps the part of a title before its " - " subtitle and
+// truncates it to fit inside a map node.
+func shortTitle(title string) string {
+	if before, _, ok := strings.Cut(title, " - "); ok {
+		title = before
+	}
+	runes := []rune(title)
+	if len(runes) > mapTitleRunes {
+		return strings.TrimSpace(string(runes[:mapTitleRunes-1])) + "…"
+	}
+	return title
+}
+
+// generateMapFile writes the workshop map as a standalone map.svg.
+func generateMapFile(outputDir string, lang LangConfig, exercises []Exercise) error {
+	svg := workshopMap(exercises, lang.UIStrings.Exercise)
+	outputPath := filepath.Join(outputDir, "map.svg")
+	content := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + svg + "\n"
+	if err := os.WriteFile(outputPath, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("writing map file: %w", err)
+	}
+
+	fmt.Printf("✓ Generated map.svg [%s]\n", lang.Code)
+	return nil
+}
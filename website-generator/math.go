@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mathClass marks elements holding TeX source; the exercise template has
+// KaTeX render every one of them in the browser.
+const mathClass = `class="math`
+
+var (
+	// displayMathRe matches $$...$$, which may span lines
+	displayMathRe = regexp.MustCompile(`(?s)\$\$(.+?)\$\$`)
+	// inlineMathRe matches $...$ where the content neither starts nor ends
+	// with a space, so prices ("$5 or $10") and shell prompts ("$ go
+	// build") are left alone
+	inlineMathRe = regexp.MustCompile(`\$([^\s$](?:[^$\n]*[^\s$\\])?)\$`)
+	// mathPlaceholderRe matches the stand-ins extractMath leaves in the
+	// markdown
+	mathPlaceholderRe = regexp.MustCompile(`MATHSPAN([0-9]+)END`)
+	// codeFenceRe matches the opening or closing line of a fenced code block
+	codeFenceRe = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// mathSpan is a formula taken out of the markdown by extractMath.
+type mathSpan struct {
+	tex     string
+	display bool
+}
+
+// extractMath replaces $...$ and $$...$$ formulas in markdown with
+// placeholders, so the markdown renderer doesn't treat their underscores
+// and asterisks as emphasis, and returns the formulas in order. Dollars in
+// fenced, indented or inline code are not delimiters, and neither is an
+// escaped \$.
+func extractMath(markdown []byte) ([]byte, []mathSpan) {
+	var spans []mathSpan
+	var out, text strings.Builder
+	flush := func() {
+		out.WriteString(extractMathText(text.String(), &spans))
+		text.Reset()
+	}
+
+	fence := ""
+	prevBlank, inIndented := true, false
+	for _, line := range strings.SplitAfter(string(markdown), "\n") {
+		if fence != "" {
+			if m := codeFenceRe.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) {
+				fence = ""
+			}
+			out.WriteString(line)
+			continue
+		}
+		if m := codeFenceRe.FindStringSubmatch(line); m != nil {
+			flush()
+			fence = m[1]
+			out.WriteString(line)
+			continue
+		}
+
+		blank := strings.TrimSpace(line) == ""
+		indented := !blank && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"))
+		inIndented = indented && (prevBlank || inIndented)
+		prevBlank = blank
+		if inIndented {
+			flush()
+			out.WriteString(line)
+			continue
+		}
+		text.WriteString(line)
+	}
+	flush()
+	return []byte(out.String()), spans
+}
+
+// extractMathText replaces the formulas in a run of markdown text, skipping
+// inline code spans.
+func extractMathText(text string, spans *[]mathSpan) string {
+	var out strings.Builder
+	for text != "" {
+		start := strings.IndexByte(text, '`')
+		if start < 0 {
+			out.WriteString(replaceMath(text, spans))
+			break
+		}
+		out.WriteString(replaceMath(text[:start], spans))
+		text = text[start:]
+
+		// A code span ends at the next run of exactly as many backticks
+		n := len(text) - len(strings.TrimLeft(text, "`"))
+		end := -1
+		for i := n; i < len(text); {
+			j := strings.IndexByte(text[i:], '`')
+			if j < 0 {
+				break
+			}
+			i += j
+			run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+			if run == n {
+				end = i + run
+				break
+			}
+			i += run
+		}
+		if end < 0 {
+			end = n
+		}
+		out.WriteString(text[:end])
+		text = text[end:]
+	}
+	return out.String()
+}
+
+// replaceMath replaces the formulas in text that contains no code.
+func replaceMath(text string, spans *[]mathSpan) string {
+	placeholder := func(tex string, display bool) string {
+		*spans = append(*spans, mathSpan{tex: tex, display: display})
+		return fmt.Sprintf("MATHSPAN%dEND", len(*spans)-1)
+	}
+	escaped := func(text string, dollar int) bool {
+		return dollar > 0 && text[dollar-1] == '\\'
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, m := range displayMathRe.FindAllStringSubmatchIndex(text, -1) {
+		if escaped(text, m[0]) {
+			continue
+		}
+		out.WriteString(replaceInlineMath(text[last:m[0]], placeholder, escaped))
+		out.WriteString(placeholder(strings.TrimSpace(text[m[2]:m[3]]), true))
+		last = m[1]
+	}
+	out.WriteString(replaceInlineMath(text[last:], placeholder, escaped))
+	return out.String()
+}
+
+func replaceInlineMath(text string, placeholder func(string, bool) string, escaped func(string, int) bool) string {
+	var out strings.Builder
+	last := 0
+	for _, m := range inlineMathRe.FindAllStringSubmatchIndex(text, -1) {
+		// "$5 to $10" style amounts: a closing dollar followed by a digit
+		// isn't one
+		if escaped(text, m[0]) || (m[1] < len(text) && text[m[1]] >= '0' && text[m[1]] <= '9') {
+			continue
+		}
+		out.WriteString(text[last:m[0]])
+		out.WriteString(placeholder(text[m[2]:m[3]], false))
+		last = m[1]
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// restoreMath puts the formulas back into the rendered HTML as elements
+// for KaTeX to render.
+func restoreMath(rendered string, spans []mathSpan) string {
+	if len(spans) == 0 {
+		return rendered
+	}
+	return mathPlaceholderRe.ReplaceAllStringFunc(rendered, func(match string) string {
+		i, _ := strconv.Atoi(mathPlaceholderRe.FindStringSubmatch(match)[1])
+		if i >= len(spans) {
+			return match
+		}
+		class := "math"
+		if spans[i].display {
+			class = "math math-display"
+		}
+		return fmt.Sprintf(`<span class="%s">%s</span>`, class, html.EscapeString(spans[i].tex))
+	})
+}
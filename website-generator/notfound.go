@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// notFoundExercise is one recovery link on the 404 page.
+type notFoundExercise struct {
+	Number      int
+	Title       string
+	Emoji       string
+	Description string
+	URL         string
+}
+
+// generate404Page writes 404.html at the output root for static hosts to
+// serve on missing paths. Those paths can be at any depth, so every link
+// is absolute: under the base URL when one is given, otherwise from the
+// host root.
+func generate404Page(outputDir string, lang LangConfig, exercises []Exercise, opts buildOptions) error {
+	siteBase := "/"
+	if opts.BaseURL != "" {
+		siteBase = strings.TrimSuffix(opts.BaseURL, "/") + "/"
+	}
+	langBase := siteBase
+	if lang.OutputPrefix != "" {
+		langBase += lang.OutputPrefix + "/"
+	}
+	homeURL := langBase
+	if home := opts.URLs.pageURL("index"); home != "./" {
+		homeURL += home
+	}
+
+	links := make([]notFoundExercise, len(exercises))
+	for i, ex := range exercises {
+		links[i] = notFoundExercise{
+			Number:      ex.Number,
+			Title:       ex.Title,
+			Emoji:       ex.Emoji,
+			Description: ex.Description,
+			URL:         langBase + ex.URL,
+		}
+	}
+
+	tmpl, _, err := parseTemplate("404", notFoundTemplate, "")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(outputDir, "404.html"))
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer f.Close()
+
+	data := struct {
+		Lang      string
+		UI        UIStrings
+		CSSPath   string
+		Favicon   template.URL
+		AssetBase string
+		HomeURL   string
+		Exercises []notFoundExercise
+	}{
+		Lang:      lang.Code,
+		UI:        lang.UIStrings,
+		CSSPath:   siteBase + "style.css",
+		Favicon:   faviconURL(siteBase, opts),
+		AssetBase: assetBase(opts.Offline, siteBase),
+		HomeURL:   homeURL,
+		Exercises: links,
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+
+	fmt.Printf("✓ Generated 404.html [%s]\n", lang.Code)
+	return nil
+}
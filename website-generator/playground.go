@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// playgroundShareURL is the Go Playground endpoint that stores a snippet
+// and answers with its id, which playgroundURL turns into a link.
+const (
+	playgroundShareURL = "https://go.dev/_/share"
+	playgroundURL      = "https://go.dev/play/p/"
+)
+
+// playgroundCacheFile remembers the ids of shared snippets, like
+// .linkcache does for external links, in the working directory.
+const playgroundCacheFile = ".playgroundcache"
+
+var (
+	// playgroundPackageRe and playgroundMainRe recognize a complete program,
+	// the only kind the playground can run
+	playgroundPackageRe = regexp.MustCompile(`(?m)^package main\b`)
+	playgroundMainRe    = regexp.MustCompile(`(?m)^func main\(\)`)
+)
+
+// playgroundLinks shares the complete Go programs in the exercises with the
+// Go Playground at build time. Shared snippets never change, so their ids
+// are remembered in a cache file and only new snippets hit the network.
+// After a failed share no more are attempted in the build. A nil
+// playgroundLinks adds no links.
+type playgroundLinks struct {
+	cacheFile string
+	client    *http.Client
+	diags     *diagnostics
+
+	// mu serializes shares from pages rendered in parallel
+	mu     sync.Mutex
+	ids    map[string]string
+	dirty  bool
+	failed bool
+}
+
+// newPlaygroundLinks loads the ids shared by earlier builds from cacheFile.
+// A missing or unreadable cache starts empty.
+func newPlaygroundLinks(cacheFile string, diags *diagnostics) *playgroundLinks {
+	p := &playgroundLinks{
+		cacheFile: cacheFile,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		diags:     diags,
+		ids:       make(map[string]string),
+	}
+	content, err := os.ReadFile(cacheFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "⚠️  Ignoring playground cache %s: %v\n", cacheFile, err)
+		}
+		return p
+	}
+	if err := json.Unmarshal(content, &p.ids); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Ignoring playground cache %s: %v\n", cacheFile, err)
+		p.ids = make(map[string]string)
+	}
+	return p
+}
+
+// isCompleteProgram reports whether code is a main package with a main
+// function.
+func isCompleteProgram(code string) bool {
+	return playgroundPackageRe.MatchString(code) && playgroundMainRe.MatchString(code)
+}
+
+// writeRunLink writes a link running the Go code block node in the
+// playground, if it is a complete program and could be shared.
+func (p *playgroundLinks) writeRunLink(w io.Writer, node *blackfriday.Node) {
+	if p == nil || codeBlockLang(node) != "go" {
+		return
+	}
+	code := string(node.Literal)
+	if !isCompleteProgram(code) {
+		return
+	}
+	id, ok := p.share(code)
+	if !ok {
+		return
+	}
+	fmt.Fprintf(w, "<p class=\"playground-run\"><a href=\"%s\" target=\"_blank\" rel=\"noopener\">▶ Run</a></p>\n", html.EscapeString(playgroundURL+id))
+}
+
+// share returns the playground id of code, sharing it unless a previous
+// build did.
+func (p *playgroundLinks) share(code string) (string, bool) {
+	key := contentHash([]byte(code))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id, ok := p.ids[key]; ok {
+		return id, true
+	}
+	if p.failed {
+		return "", false
+	}
+
+	id, err := p.post(code)
+	if err != nil {
+		p.failed = true
+		p.diags.warnf(categoryBuild, "", "sharing snippets with the Go Playground failed, Run links are omitted: %v", err)
+		return "", false
+	}
+	p.ids[key] = id
+	p.dirty = true
+	return id, true
+}
+
+func (p *playgroundLinks) post(code string) (string, error) {
+	resp, err := p.client.Post(playgroundShareURL, "text/plain; charset=utf-8", strings.NewReader(code))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", playgroundShareURL, resp.Status)
+	}
+	id := strings.TrimSpace(string(body))
+	if id == "" || strings.ContainsAny(id, "/?# \n") {
+		return "", fmt.Errorf("%s: unexpected response %q", playgroundShareURL, id)
+	}
+	return id, nil
+}
+
+// save writes the cache if this build shared new snippets.
+func (p *playgroundLinks) save() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.dirty {
+		return nil
+	}
+	content, err := json.MarshalIndent(p.ids, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding playground cache: %w", err)
+	}
+	if err := os.WriteFile(p.cacheFile, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing playground cache: %w", err)
+	}
+	p.dirty = false
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// preflightInputs are the files and directories a build reads, as given on
+// the command line; empty ones are not used.
+type preflightInputs struct {
+	ExercisesDir     string
+	ConfigFile       string
+	TransformsFile   string
+	ExerciseTemplate string
+	IndexTemplate    string
+	CSSFile          string
+	Favicon          string
+	// PWAIcons is the comma-separated -pwa-icons list
+	PWAIcons string
+}
+
+// preflight checks that every input exists before anything is generated,
+// including the exercises an exercises config lists, and reports all the
+// missing ones in one error. A misconfigured build then writes nothing
+// instead of stopping halfway through the output directory. Malformed
+// inputs are left for their loaders to report.
+func preflight(in preflightInputs) error {
+	var missing []string
+	if info, err := os.Stat(in.ExercisesDir); err != nil || !info.IsDir() {
+		missing = append(missing, in.ExercisesDir+" (exercises directory)")
+	}
+	for _, input := range []struct{ path, what string }{
+		{in.ConfigFile, "exercises config"},
+		{in.TransformsFile, "transforms file"},
+		{in.ExerciseTemplate, "exercise template"},
+		{in.IndexTemplate, "index template"},
+		{in.CSSFile, "stylesheet"},
+		{in.Favicon, "favicon"},
+	} {
+		if input.path == "" {
+			continue
+		}
+		if _, err := os.Stat(input.path); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%s)", input.path, input.what))
+		}
+	}
+
+	for _, icon := range strings.Split(in.PWAIcons, ",") {
+		icon = strings.TrimSpace(icon)
+		if icon == "" {
+			continue
+		}
+		if _, err := os.Stat(icon); err != nil {
+			missing = append(missing, icon+" (PWA icon)")
+		}
+	}
+
+	if in.ConfigFile != "" {
+		if content, err := os.ReadFile(in.ConfigFile); err == nil {
+			var config exerciseConfig
+			if yaml.Unmarshal(content, &config) == nil {
+				for _, path := range config.missingFiles(in.ExercisesDir) {
+					missing = append(missing, path+" (listed in "+in.ConfigFile+")")
+				}
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing input files, nothing was generated:\n  %s", strings.Join(missing, "\n  "))
+	}
+	return nil
+}
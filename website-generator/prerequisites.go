@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// checkPrerequisites reports, as build errors, prerequisites that name no
+// exercise of the language and prerequisites that depend on each other in
+// a cycle, which readers could never satisfy.
+func checkPrerequisites(lang LangConfig, exercises []Exercise, diags *diagnostics) {
+	for _, ex := range exercises {
+		for _, name := range ex.Prerequisites {
+			if !slices.ContainsFunc(lang.Metadata, func(m exerciseMeta) bool { return m.Filename == name }) {
+				diags.errorf(categoryBuild, lang.sourceFile(ex.Name), "prerequisite %q not found", name)
+			}
+		}
+	}
+
+	for _, cycle := range prerequisiteCycles(exercises) {
+		diags.errorf(categoryBuild, lang.sourceFile(cycle[0]), "prerequisite cycle: %s", strings.Join(cycle, " → "))
+	}
+}
+
+// prerequisiteCycles returns each cycle in the prerequisite graph once, as
+// the exercise names along it with the first repeated at the end.
+func prerequisiteCycles(exercises []Exercise) [][]string {
+	prereqs := make(map[string][]string, len(exercises))
+	for _, ex := range exercises {
+		prereqs[ex.Name] = ex.Prerequisites
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(exercises))
+	var cycles [][]string
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, next := range prereqs[name] {
+			switch state[next] {
+			case visiting:
+				start := slices.Index(path, next)
+				cycle := append(slices.Clone(path[start:]), next)
+				cycles = append(cycles, cycle)
+			case unvisited:
+				if _, ok := prereqs[next]; ok {
+					visit(next)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+	for _, ex := range exercises {
+		if state[ex.Name] == unvisited {
+			visit(ex.Name)
+		}
+	}
+	return cycles
+}
+
+// prerequisiteGraph is prerequisites.json: every exercise, and an edge from
+// each prerequisite to the exercise needing it.
+type prerequisiteGraph struct {
+	Nodes []prerequisiteNode `json:"nodes"`
+	Edges []prerequisiteEdge `json:"edges"`
+}
+
+type prerequisiteNode struct {
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+type prerequisiteEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// generatePrerequisites writes prerequisites.json for a language, next to
+// its index page, for tools that visualize the order exercises build on
+// each other in.
+func generatePrerequisites(outputDir string, exercises []Exercise) error {
+	graph := prerequisiteGraph{
+		Nodes: make([]prerequisiteNode, len(exercises)),
+		Edges: []prerequisiteEdge{},
+	}
+	for i, ex := range exercises {
+		graph.Nodes[i] = prerequisiteNode{Name: ex.Name, Number: ex.Number, Title: ex.Title, URL: ex.URL}
+		for _, name := range ex.Prerequisites {
+			graph.Edges = append(graph.Edges, prerequisiteEdge{From: name, To: ex.Name})
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(graph); err != nil {
+		return fmt.Errorf("encoding prerequisites: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "prerequisites.json"), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing prerequisites: %w", err)
+	}
+
+	fmt.Printf("✓ Generated prerequisites.json [%s]\n", exercises[0].Lang)
+	return nil
+}
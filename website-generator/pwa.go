@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pwaThemeColor is the browser chrome color of the installed workshop, the
+// stylesheet's --primary-color, and pwaBackgroundColor its splash screen
+// background, --light-bg.
+const (
+	pwaThemeColor      = "#00ADD8"
+	pwaBackgroundColor = "#f8f9fa"
+)
+
+// pwaPrecacheExts are the kinds of generated files sw.js precaches: pages,
+// styles and scripts, the search indexes, images, and the fonts and WASM
+// binaries of -offline and -wasm builds.
+var pwaPrecacheExts = map[string]bool{
+	".html": true, ".css": true, ".js": true, ".json": true, ".webmanifest": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true,
+	".woff2": true, ".wasm": true,
+}
+
+// pwaIcon is an icon given with -pwa-icons, as listed in the web app
+// manifest.
+type pwaIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes"`
+	Type  string `json:"type"`
+
+	// file is the icon's source file
+	file string
+}
+
+// webManifest is manifest.webmanifest, which lets browsers install the
+// workshop as an app.
+type webManifest struct {
+	Name            string    `json:"name"`
+	ShortName       string    `json:"short_name"`
+	Description     string    `json:"description"`
+	Lang            string    `json:"lang"`
+	StartURL        string    `json:"start_url"`
+	Scope           string    `json:"scope"`
+	Display         string    `json:"display"`
+	ThemeColor      string    `json:"theme_color"`
+	BackgroundColor string    `json:"background_color"`
+	Icons           []pwaIcon `json:"icons"`
+}
+
+// loadPWAIcons reads the comma-separated PNG files of -pwa-icons, taking
+// each one's size from the image itself.
+func loadPWAIcons(list string) ([]pwaIcon, error) {
+	var icons []pwaIcon
+	for _, file := range strings.Split(list, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading PWA icon: %w", err)
+		}
+		config, err := png.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading PWA icon %s: not a PNG image: %w", file, err)
+		}
+		icons = append(icons, pwaIcon{
+			Src:   "icons/" + filepath.Base(file),
+			Sizes: fmt.Sprintf("%dx%d", config.Width, config.Height),
+			Type:  "image/png",
+			file:  file,
+		})
+	}
+	if len(icons) == 0 {
+		return nil, fmt.Errorf("-pwa needs at least one icon in -pwa-icons")
+	}
+	return icons, nil
+}
+
+// generatePWA writes the web app manifest, with its icons, and sw.js at the
+// output root. It runs after everything else is written, since the service
+// worker precaches every generated page, stylesheet and asset; its cache is
+// named after their contents, so browsers pick up a new build on their next
+// visit and drop the old one.
+func generatePWA(outputDir string, opts buildOptions) error {
+	if err := os.MkdirAll(filepath.Join(outputDir, "icons"), 0o755); err != nil {
+		return fmt.Errorf("creating icons directory: %w", err)
+	}
+	for _, icon := range opts.pwaIcons {
+		content, err := os.ReadFile(icon.file)
+		if err != nil {
+			return fmt.Errorf("reading PWA icon: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, filepath.FromSlash(icon.Src)), content, 0o644); err != nil {
+			return fmt.Errorf("writing PWA icon: %w", err)
+		}
+	}
+
+	// The app opens on the index of the first language built
+	lang := opts.Languages[0]
+	manifest := webManifest{
+		Name:            lang.UIStrings.HeroTitle,
+		ShortName:       "Go Workshop",
+		Description:     lang.UIStrings.HeroLead,
+		Lang:            lang.Code,
+		StartURL:        absoluteURL(opts.BaseURL, lang, opts.URLs.pageURL("index")),
+		Scope:           strings.TrimSuffix(opts.BaseURL, "/") + "/",
+		Display:         "standalone",
+		ThemeColor:      pwaThemeColor,
+		BackgroundColor: pwaBackgroundColor,
+		Icons:           opts.pwaIcons,
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return fmt.Errorf("encoding web app manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "manifest.webmanifest"), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing web app manifest: %w", err)
+	}
+
+	precache, version, err := pwaPrecache(outputDir)
+	if err != nil {
+		return err
+	}
+	urls, err := json.Marshal(precache)
+	if err != nil {
+		return fmt.Errorf("encoding service worker: %w", err)
+	}
+	sw := fmt.Sprintf(serviceWorkerJS, "workshop-"+version[:16], urls)
+	if err := os.WriteFile(filepath.Join(outputDir, "sw.js"), []byte(sw), 0o644); err != nil {
+		return fmt.Errorf("writing service worker: %w", err)
+	}
+
+	fmt.Printf("✓ Generated manifest.webmanifest and sw.js (%d files precached)\n", len(precache))
+	return nil
+}
+
+// pwaPrecache lists the generated files sw.js precaches, as URLs relative to
+// it, along with a hash of their contents. Index pages are listed under
+// their directory URL too, which is how links reach them.
+func pwaPrecache(outputDir string) ([]string, string, error) {
+	var urls []string
+	var digest bytes.Buffer
+	err := filepath.WalkDir(outputDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !pwaPrecacheExts[filepath.Ext(p)] || entry.Name() == "sw.js" || entry.Name() == buildCacheFile {
+			return nil
+		}
+		rel, err := filepath.Rel(outputDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&digest, "%s %s\n", rel, contentHash(content))
+
+		urls = append(urls, "./"+rel)
+		if path.Base(rel) == "index.html" {
+			urls = append(urls, "./"+strings.TrimSuffix(rel, "index.html"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("listing files to precache: %w", err)
+	}
+	return urls, contentHash(digest.Bytes()), nil
+}
+
+// serviceWorkerJS is sw.js, formatted with the cache name and the URLs to
+// precache. Precached files are answered from the cache first, so every
+// page opens offline; anything else goes to the network.
+const serviceWorkerJS = `const CACHE = %q;
+const PRECACHE = %s;
+
+self.addEventListener('install', function(event) {
+    event.waitUntil(caches.open(CACHE).then(function(cache) {
+        return cache.addAll(PRECACHE);
+    }).then(function() {
+        return self.skipWaiting();
+    }));
+});
+
+// Caches of earlier builds are dropped once this one takes over
+self.addEventListener('activate', function(event) {
+    event.waitUntil(caches.keys().then(function(keys) {
+        return Promise.all(keys.filter(function(key) {
+            return key !== CACHE;
+        }).map(function(key) {
+            return caches.delete(key);
+        }));
+    }).then(function() {
+        return self.clients.claim();
+    }));
+});
+
+self.addEventListener('fetch', function(event) {
+    if (event.request.method !== 'GET') {
+        return;
+    }
+    event.respondWith(caches.match(event.request, {ignoreSearch: true}).then(function(cached) {
+        return cached || fetch(event.request);
+    }));
+});
+`
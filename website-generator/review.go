@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// reviewFile is one changed exercise rendered at both revisions.
+type reviewFile struct {
+	Path string
+	// Lang is the code of the language the exercise is written in
+	Lang   string
+	Status string
+	Base   template.HTML
+	Head   template.HTML
+}
+
+// generateReview renders every exercise markdown file that changed between
+// baseRef and the working tree, and writes review.html with the base and
+// head renderings side by side. The page is in the language of langs[0].
+func generateReview(exercisesDir, outputDir, baseRef, css string, langs []LangConfig) (int, error) {
+	root, err := gitOutput(exercisesDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return 0, fmt.Errorf("locating git repository: %w", err)
+	}
+	root = strings.TrimSpace(root)
+	exercisesAbs, err := filepath.Abs(exercisesDir)
+	if err != nil {
+		return 0, fmt.Errorf("locating exercises directory: %w", err)
+	}
+
+	// -z keeps paths with spaces or other special characters whole and
+	// unquoted
+	changed, err := gitOutput(exercisesDir, "diff", "--name-only", "-z", baseRef, "--", ".")
+	if err != nil {
+		return 0, fmt.Errorf("listing changed files: %w", err)
+	}
+
+	var files []reviewFile
+	for _, path := range strings.Split(changed, "\x00") {
+		if path == "" || !isMarkdownFile(path) {
+			continue
+		}
+
+		name, err := filepath.Rel(exercisesAbs, filepath.Join(root, path))
+		if err != nil {
+			return 0, fmt.Errorf("locating %s: %w", path, err)
+		}
+
+		// Files added since the base have no base content, and deleted
+		// files have no head content.
+		base, baseErr := gitOutput(root, "show", baseRef+":"+path)
+		head, headErr := os.ReadFile(filepath.Join(root, path))
+		if headErr != nil && !errors.Is(headErr, os.ErrNotExist) {
+			return 0, fmt.Errorf("reading %s: %w", path, headErr)
+		}
+
+		file := reviewFile{Path: path, Lang: languageOfFile(exercisesDir, langs, name), Status: "modified"}
+		switch {
+		case baseErr != nil:
+			file.Status = "added"
+		case headErr != nil:
+			file.Status = "deleted"
+		}
+		if baseErr == nil {
+			if file.Base, err = renderReviewContent([]byte(base), exercisesDir, name); err != nil {
+				return 0, fmt.Errorf("%s at %s: %w", path, baseRef, err)
+			}
+		}
+		if headErr == nil {
+			if file.Head, err = renderReviewContent(head, exercisesDir, name); err != nil {
+				return 0, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	tmpl, _, err := parseTemplate("review", reviewTemplate, "")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return 0, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(outputDir, "review.html")
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return 0, fmt.Errorf("creating output file: %w", err)
+	}
+	defer f.Close()
+
+	data := struct {
+		Lang    string
+		BaseRef string
+		Files   []reviewFile
+	}{
+		Lang:    langs[0].Code,
+		BaseRef: baseRef,
+		Files:   files,
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		return 0, fmt.Errorf("executing template: %w", err)
+	}
+
+	if err := copyCSSFile(outputDir, css, buildOptions{}); err != nil {
+		return 0, err
+	}
+
+	fmt.Println("✓ Generated review.html")
+	return len(files), nil
+}
+
+// languageOfFile returns the code of the language whose exercise file
+// name, relative to the exercises directory, is: the one with its own
+// folder, or else with the longest matching suffix, so "02-x.es.md" is
+// Spanish rather than English.
+func languageOfFile(exercisesDir string, langs []LangConfig, name string) string {
+	name = filepath.ToSlash(name)
+	code, longest := langs[0].Code, 0
+	for _, lang := range langs {
+		lang = withSourceDir(exercisesDir, lang)
+		if lang.SourceDir != "" {
+			if strings.HasPrefix(name, lang.SourceDir+"/") {
+				return lang.Code
+			}
+			continue
+		}
+		if suffix := lang.fileSuffixOf(name); len(suffix) > longest {
+			code, longest = lang.Code, len(suffix)
+		}
+	}
+	return code
+}
+
+// renderReviewContent renders an exercise's markdown like its page's body:
+// the front matter is split off, includes are expanded from the working
+// tree, and collapsible sections are rendered.
+func renderReviewContent(content []byte, exercisesDir, name string) (template.HTML, error) {
+	_, body, err := parseFrontmatter(content)
+	if err != nil {
+		return "", err
+	}
+	body, err = expandIncludes(body, exercisesDir, name)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(renderMarkdownBody(body, markdownOptions{}, false)), nil
+}
+
+// gitOutput runs git in dir and returns its standard output.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// noSearchCommentRe matches content wrapped in <!-- no-search --> ...
+	// <!-- /no-search --> markers. Markdown between the markers is still
+	// rendered normally, which makes this the easiest form for authors.
+	noSearchCommentRe = regexp.MustCompile(`(?s)<!--\s*no-search\s*-->.*?<!--\s*/no-search\s*-->`)
+	noSearchClassRe   = regexp.MustCompile(`class="[^"]*\bno-search\b[^"]*"`)
+	htmlTagRe         = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+	// codeBlockRe matches rendered code blocks, which are left out of the
+	// index so results aren't dominated by code
+	codeBlockRe = regexp.MustCompile(`(?s)<pre[\s>].*?</pre>`)
+	// headingAnchorRe matches the "#" links added to headings
+	headingAnchorRe = regexp.MustCompile(`<a class="heading-anchor"[^>]*>#</a>`)
+)
+
+// searchEntry is one exercise in search-index.json.
+type searchEntry struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Text   string `json:"text"`
+}
+
+// generateSearchIndex writes search-index.json for a language, next to its
+// index page. Entries follow the exercise order, so rebuilding unchanged
+// content produces an identical file.
+func generateSearchIndex(outputDir string, lang LangConfig, exercises []Exercise) error {
+	entries := make([]searchEntry, len(exercises))
+	for i, ex := range exercises {
+		entries[i] = searchEntry{
+			Number: ex.Number,
+			Title:  ex.Title,
+			URL:    ex.URL,
+			Text:   searchableText(string(ex.Content)),
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("encoding search index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "search-index.json"), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing search index: %w", err)
+	}
+
+	fmt.Printf("✓ Generated search-index.json [%s]\n", lang.Code)
+	return nil
+}
+
+// searchableText extracts the plain text of rendered exercise HTML for the
+// search index. Code blocks and sections marked as excluded from search
+// (solutions, instructor notes) are dropped so they never surface in
+// results, while the rendered page keeps them.
+func searchableText(rendered string) string {
+	text := stripNoSearch(rendered)
+	text = codeBlockRe.ReplaceAllString(text, " ")
+	text = headingAnchorRe.ReplaceAllString(text, "")
+	text = htmlTagRe.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// stripNoSearch removes no-search comment blocks and any element carrying
+// the no-search class, including everything nested inside it.
+func stripNoSearch(rendered string) string {
+	rendered = noSearchCommentRe.ReplaceAllString(rendered, "")
+
+	var b strings.Builder
+	last := 0
+	skipTag := ""
+	depth := 0
+	for _, m := range htmlTagRe.FindAllStringSubmatchIndex(rendered, -1) {
+		closing := rendered[m[2]:m[3]] == "/"
+		name := strings.ToLower(rendered[m[4]:m[5]])
+		attrs := rendered[m[6]:m[7]]
+
+		if depth == 0 {
+			if !closing && noSearchClassRe.MatchString(attrs) {
+				b.WriteString(rendered[last:m[0]])
+				skipTag = name
+				depth = 1
+			}
+			continue
+		}
+
+		if name != skipTag {
+			continue
+		}
+		if closing {
+			depth--
+		} else if !strings.HasSuffix(attrs, "/") {
+			depth++
+		}
+		if depth == 0 {
+			last = m[1]
+		}
+	}
+
+	// An unterminated excluded element swallows the rest of the page
+	if depth == 0 {
+		b.WriteString(rendered[last:])
+	}
+	return b.String()
+}
@@ -2,182 +2,147 @@ package main
 
 import (
 	"fmt"
+	"mime"
+	"net"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
-	"time"
 )
 
-type devServer struct {
-	exercisesDir string
-	outputDir    string
-	port         int
+// liveReloadPath is the server-sent events endpoint pages listen on for
+// rebuilds.
+const liveReloadPath = "/--livereload"
 
-	mu        sync.Mutex
-	clients   map[chan struct{}]struct{}
-	debounce  *time.Timer
-}
+// liveReloadScript is injected into every served HTML page when the server
+// runs with -watch. EventSource reconnects on its own after the server
+// restarts, and the page reloads once it is back.
+const liveReloadScript = `<script>
+(function() {
+    var es = new EventSource('` + liveReloadPath + `');
+    var lost = false;
+    es.onmessage = function(e) {
+        if (e.data === 'reload' || (e.data === 'connected' && lost)) {
+            window.location.reload();
+        }
+    };
+    es.onerror = function() {
+        lost = true;
+    };
+})();
+</script>`
 
-func newDevServer(exercisesDir, outputDir string, port int) *devServer {
-	return &devServer{
-		exercisesDir: exercisesDir,
-		outputDir:    outputDir,
-		port:         port,
-		clients:      make(map[chan struct{}]struct{}),
+func init() {
+	// Don't depend on the system MIME tables for the types the site uses
+	for ext, typ := range map[string]string{
+		".css":  "text/css; charset=utf-8",
+		".js":   "text/javascript; charset=utf-8",
+		".json": "application/json",
+		".svg":  "image/svg+xml",
+		".wasm": "application/wasm",
+		".xml":  "text/xml; charset=utf-8",
+	} {
+		mime.AddExtensionType(ext, typ)
 	}
 }
 
-func (s *devServer) run() error {
-	// Initial build
-	if err := s.rebuild(); err != nil {
-		return fmt.Errorf("initial build failed: %w", err)
-	}
-
-	// Start file watcher
-	go s.watch()
-
-	// Serve files with live reload injection
-	mux := http.NewServeMux()
-	mux.HandleFunc("/--livereload", s.sseHandler)
-	mux.Handle("/", s.injectLiveReload(http.FileServer(http.Dir(s.outputDir))))
-
-	addr := fmt.Sprintf(":%d", s.port)
-	fmt.Printf("🌐 Dev server running at http://localhost:%d\n", s.port)
-	fmt.Printf("👀 Watching %s for changes...\n", s.exercisesDir)
-	return http.ListenAndServe(addr, mux)
+// serveFlag is the -serve flag. It takes an address, -serve=:8080, or
+// given alone, as before it took one, serves on the -port port.
+type serveFlag struct {
+	set  bool
+	addr string
 }
 
-func (s *devServer) rebuild() error {
-	fmt.Println("🔄 Rebuilding website...")
-
-	if err := os.MkdirAll(s.outputDir, 0755); err != nil {
-		return err
-	}
-
-	for _, lang := range languages {
-		langOutputDir := s.outputDir
-		if lang.OutputPrefix != "" {
-			langOutputDir = filepath.Join(s.outputDir, lang.OutputPrefix)
-			if err := os.MkdirAll(langOutputDir, 0755); err != nil {
-				return err
-			}
-		}
-
-		cssPath := "style.css"
-		if lang.OutputPrefix != "" {
-			cssPath = "../style.css"
-		}
-
-		homePath := ""
-		altLangURLPrefix := "../"
-		if lang.OutputPrefix == "" {
-			altLangURLPrefix = "es/"
-		}
-
-		exercises := make([]Exercise, 0, len(lang.Metadata))
-		for i, meta := range lang.Metadata {
-			exercise, err := generateExercisePage(s.exercisesDir, langOutputDir, lang, meta, i, cssPath, homePath, altLangURLPrefix)
-			if err != nil {
-				return err
-			}
-			exercises = append(exercises, exercise)
-		}
-
-		if err := generateIndexPage(langOutputDir, lang, exercises, cssPath, homePath, altLangURLPrefix); err != nil {
-			return err
-		}
-	}
+func (f *serveFlag) String() string {
+	return f.addr
+}
 
-	if err := copyCSSFile(s.outputDir); err != nil {
-		return err
+func (f *serveFlag) Set(value string) error {
+	switch value {
+	case "true":
+		f.set, f.addr = true, ""
+	case "false":
+		f.set, f.addr = false, ""
+	default:
+		f.set, f.addr = true, value
 	}
-
-	fmt.Println("✅ Rebuild complete")
 	return nil
 }
 
-func (s *devServer) watch() {
-	// Poll for changes by tracking modification times
-	modTimes := make(map[string]time.Time)
-
-	// Seed initial mod times
-	s.scanModTimes(modTimes)
-
-	for {
-		time.Sleep(500 * time.Millisecond)
+// IsBoolFlag lets -serve be given without a value.
+func (f *serveFlag) IsBoolFlag() bool {
+	return true
+}
 
-		changed := false
-		current := make(map[string]time.Time)
-		s.scanModTimes(current)
+// address returns where to serve, empty when -serve isn't set.
+func (f *serveFlag) address(port int) string {
+	if !f.set || f.addr != "" {
+		return f.addr
+	}
+	return ":" + strconv.Itoa(port)
+}
 
-		// Check for new or modified files
-		for path, modTime := range current {
-			if prev, ok := modTimes[path]; !ok || !modTime.Equal(prev) {
-				changed = true
-				break
-			}
-		}
+// devServer serves the output directory for local previews. With
+// liveReload set, HTML pages get a script that reloads them after every
+// rebuild.
+type devServer struct {
+	outputDir  string
+	liveReload bool
 
-		// Check for deleted files
-		if !changed {
-			for path := range modTimes {
-				if _, ok := current[path]; !ok {
-					changed = true
-					break
-				}
-			}
-		}
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
 
-		if changed {
-			modTimes = current
-			s.debouncedRebuild()
-		}
+func newDevServer(outputDir string, liveReload bool) *devServer {
+	return &devServer{
+		outputDir:  outputDir,
+		liveReload: liveReload,
+		clients:    make(map[chan struct{}]struct{}),
 	}
 }
 
-func (s *devServer) scanModTimes(out map[string]time.Time) {
-	// Watch exercises directory
-	filepath.Walk(s.exercisesDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && filepath.Ext(path) == ".md" {
-			out[path] = info.ModTime()
-		}
-		return nil
-	})
-
-	// Also watch the generator source files themselves
-	filepath.Walk(filepath.Dir(os.Args[0]), func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() && filepath.Ext(path) == ".go" {
-			out[path] = info.ModTime()
-		}
-		return nil
-	})
+// listen opens addr for the server. It is separate from serving so a busy
+// port is reported before a watch build starts.
+func (s *devServer) listen(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("🌐 Serving %s at http://%s\n", s.outputDir, displayAddr(ln.Addr()))
+	return ln, nil
 }
 
-func (s *devServer) debouncedRebuild() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// serve serves requests on ln until it fails.
+func (s *devServer) serve(ln net.Listener) error {
+	return http.Serve(ln, s.handler())
+}
 
-	if s.debounce != nil {
-		s.debounce.Stop()
+// displayAddr spells a listening address the way a browser wants it,
+// e.g. "localhost:8080" for ":8080".
+func displayAddr(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
 	}
+	return net.JoinHostPort(host, port)
+}
 
-	s.debounce = time.AfterFunc(200*time.Millisecond, func() {
-		if err := s.rebuild(); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Rebuild error: %v\n", err)
-			return
-		}
-		s.notifyClients()
-	})
+func (s *devServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	if s.liveReload {
+		mux.HandleFunc(liveReloadPath, s.sseHandler)
+	}
+	mux.HandleFunc("/", s.fileHandler)
+	return mux
 }
 
+// notifyClients tells every connected page to reload.
 func (s *devServer) notifyClients() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -200,7 +165,6 @@ func (s *devServer) sseHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	ch := make(chan struct{}, 1)
 	s.mu.Lock()
@@ -228,53 +192,59 @@ func (s *devServer) sseHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *devServer) injectLiveReload(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// For non-HTML requests, serve directly
-		if filepath.Ext(r.URL.Path) != ".html" && r.URL.Path != "/" && filepath.Ext(r.URL.Path) != "" {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// For HTML files, read and inject the live reload script
-		path := r.URL.Path
-		if path == "/" {
-			path = "/index.html"
-		}
-		if filepath.Ext(path) == "" {
-			path += ".html"
-		}
-
-		filePath := filepath.Join(s.outputDir, filepath.Clean(path))
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			next.ServeHTTP(w, r)
+// fileHandler serves a file from the output directory. Paths resolve the
+// way static hosts resolve them: directories to their index.html and
+// extensionless clean URLs to the .html page. Missing paths get 404.html
+// when the site has one.
+func (s *devServer) fileHandler(w http.ResponseWriter, r *http.Request) {
+	file, ok := s.resolve(r.URL.Path)
+	status := http.StatusOK
+	if !ok {
+		file, ok = s.resolve("/404.html")
+		status = http.StatusNotFound
+		if !ok {
+			http.NotFound(w, r)
 			return
 		}
+	}
 
-		// Inject live reload script before </body>
-		script := `<script>
-(function() {
-    var es = new EventSource('/--livereload');
-    es.onmessage = function(e) {
-        if (e.data === 'reload') {
-            window.location.reload();
-        }
-    };
-    es.onerror = function() {
-        setTimeout(function() { window.location.reload(); }, 1000);
-    };
-})();
-</script>`
+	w.Header().Set("Cache-Control", "no-cache")
+	if status == http.StatusOK && (!s.liveReload || filepath.Ext(file) != ".html") {
+		http.ServeFile(w, r, file)
+		return
+	}
 
-		html := string(content)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	html := string(content)
+	if s.liveReload {
 		if idx := strings.LastIndex(html, "</body>"); idx >= 0 {
-			html = html[:idx] + script + html[idx:]
+			html = html[:idx] + liveReloadScript + html[idx:]
 		} else {
-			html += script
+			html += liveReloadScript
 		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprint(w, html)
+}
 
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		fmt.Fprint(w, html)
-	})
+// resolve maps a URL path to a file in the output directory.
+func (s *devServer) resolve(urlPath string) (string, bool) {
+	file := filepath.Join(s.outputDir, filepath.FromSlash(path.Clean("/"+urlPath)))
+	info, err := os.Stat(file)
+	switch {
+	case err == nil && info.IsDir():
+		file = filepath.Join(file, "index.html")
+	case err != nil && filepath.Ext(file) == "":
+		file += ".html"
+	}
+	info, err = os.Stat(file)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return file, true
 }
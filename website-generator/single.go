@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// singlePageSection is one exercise in all.html.
+type singlePageSection struct {
+	ID      string
+	Number  int
+	Title   string
+	Content template.HTML
+}
+
+// generateSinglePage writes all.html next to a language's index page, with
+// every exercise in order under its own section and a table of contents
+// linking to each. Links between exercises become in-page anchors.
+func generateSinglePage(outputDir string, lang LangConfig, exercises []Exercise, siteRoot string, opts buildOptions) error {
+	sections := make([]singlePageSection, len(exercises))
+	for i, ex := range exercises {
+		sections[i] = singlePageSection{
+			ID:      ex.Name,
+			Number:  ex.Number,
+			Title:   ex.Title,
+			Content: template.HTML(singlePageLinks(ex.pageHTML, ex.Name, opts.URLs)),
+		}
+	}
+
+	tmpl, _, err := parseTemplate("single", singlePageTemplate, "")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(outputDir, "all.html"))
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	defer f.Close()
+
+	data := struct {
+		Lang     string
+		Title    string
+		Exercise string
+		Contents string
+		CSSPath  string
+		Favicon  template.URL
+		HomeURL  string
+		Sections []singlePageSection
+	}{
+		Lang:     lang.Code,
+		Title:    lang.UIStrings.HeroTitle,
+		Exercise: lang.UIStrings.Exercise,
+		Contents: lang.UIStrings.Contents,
+		CSSPath:  siteRoot + "style.css",
+		Favicon:  faviconURL(siteRoot, opts),
+		HomeURL:  opts.URLs.pageURL("index"),
+		Sections: sections,
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	if _, err := f.Write(absoluteLinks(buf.Bytes(), pageAbsoluteURL(opts.BaseURL, lang, "all.html"))); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
+	}
+
+	fmt.Printf("✓ Generated all.html [%s]\n", lang.Code)
+	return nil
+}
+
+// singlePageLinks points links to other exercises at their section in
+// all.html, and links to the index at the index page. from is the
+// exercise the HTML belongs to.
+func singlePageLinks(html, from string, urls urlPolicy) string {
+	return renderedPageLinkRe.ReplaceAllStringFunc(html, func(match string) string {
+		m := renderedPageLinkRe.FindStringSubmatch(match)
+		switch target := resolvePageLink(from, m[1], m[2]); target {
+		case "":
+			return match
+		case "index":
+			return fmt.Sprintf(`href="%s"`, urls.pageURL("index"))
+		default:
+			return fmt.Sprintf(`href="#%s"`, target)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// generateSitemap writes sitemap.xml at the output root, listing the index
+// page and every exercise page of every language. The last modification
+// date of each exercise comes from its markdown source; an index page takes
+// the date of its most recently updated exercise.
+func generateSitemap(outputDir, baseURL string, exercises []Exercise, urls urlPolicy) error {
+	set := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+
+	for _, lang := range languages {
+		var langExercises []Exercise
+		var newest time.Time
+		for _, ex := range exercises {
+			if ex.Lang == lang.Code {
+				langExercises = append(langExercises, ex)
+				if ex.LastUpdated.After(newest) {
+					newest = ex.LastUpdated
+				}
+			}
+		}
+		if len(langExercises) == 0 {
+			continue
+		}
+
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     absoluteURL(baseURL, lang, urls.pageURL("index")),
+			LastMod: sitemapDate(newest),
+		})
+		for _, ex := range langExercises {
+			set.URLs = append(set.URLs, sitemapURL{
+				Loc:     absoluteURL(baseURL, lang, ex.URL),
+				LastMod: sitemapDate(ex.LastUpdated),
+			})
+		}
+	}
+
+	content, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding sitemap: %w", err)
+	}
+	content = append([]byte(xml.Header), content...)
+	if err := os.WriteFile(filepath.Join(outputDir, "sitemap.xml"), append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing sitemap: %w", err)
+	}
+
+	fmt.Printf("✓ Generated sitemap.xml (%d URLs)\n", len(set.URLs))
+	return nil
+}
+
+// absoluteURL joins the site's base URL, the language directory and a page
+// URL relative to that directory.
+func absoluteURL(baseURL string, lang LangConfig, pageURL string) string {
+	u := strings.TrimSuffix(baseURL, "/") + "/"
+	if lang.OutputPrefix != "" {
+		u += lang.OutputPrefix + "/"
+	}
+	if pageURL == "./" {
+		return u
+	}
+	return u + pageURL
+}
+
+// pageAbsoluteURL is absoluteURL for page metadata: empty when no base URL
+// was given, since social cards and the like need a full URL or nothing.
+func pageAbsoluteURL(baseURL string, lang LangConfig, pageURL string) string {
+	if baseURL == "" {
+		return ""
+	}
+	return absoluteURL(baseURL, lang, pageURL)
+}
+
+func sitemapDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
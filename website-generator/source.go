@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// sourceURL links the copy of an exercise's markdown that -include-source
+// places in the language directory, e.g. "02-scanner-arrow-operator.md";
+// homePath is the relative path from the page to that directory. It is
+// empty without -include-source.
+func sourceURL(homePath, name string, opts buildOptions) string {
+	if !opts.IncludeSource {
+		return ""
+	}
+	return homePath + escapePath(name) + ".md"
+}
+
+// copySource writes the exercise's markdown, front matter included, to
+// where its SourceURL points.
+func copySource(outputDir string, ex Exercise, dryRun bool) error {
+	content, err := os.ReadFile(ex.sourcePath)
+	if err != nil {
+		return fmt.Errorf("reading markdown source: %w", err)
+	}
+	if err := writeOutput(filepath.Join(outputDir, filepath.FromSlash(ex.Name)+".md"), content, dryRun); err != nil {
+		return fmt.Errorf("copying markdown source: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/sha512"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// sriFile records the Subresource Integrity hash of every CDN file the
+// templates load directly. It is compiled in, so builds never need the
+// network to check them; -refresh-sri rewrites it after a version bump.
+const sriFile = "sri.json"
+
+//go:embed sri.json
+var sriJSON []byte
+
+// sriAssets are the CDN files the templates reference with a <script> or
+// <link>. The fonts Font Awesome and KaTeX load from their stylesheets
+// can't carry an integrity attribute.
+var sriAssets = []string{
+	"font-awesome/6.5.1/css/all.min.css",
+	"mermaid/10.9.1/mermaid.min.js",
+	"KaTeX/0.16.9/katex.min.css",
+	"KaTeX/0.16.9/katex.min.js",
+}
+
+// sriHashes maps asset paths, relative to cdnBase, to their "sha384-..."
+// hashes.
+var sriHashes = map[string]string{}
+
+func init() {
+	if err := json.Unmarshal(sriJSON, &sriHashes); err != nil {
+		panic(fmt.Sprintf("parsing %s: %v", sriFile, err))
+	}
+}
+
+// sriAttrs returns the integrity and crossorigin attributes for asset when
+// it is loaded from the CDN and its hash is known. Local -offline copies
+// need none.
+func sriAttrs(base, asset string) template.HTMLAttr {
+	hash, ok := sriHashes[asset]
+	if base != cdnBase || !ok {
+		return ""
+	}
+	return template.HTMLAttr(fmt.Sprintf(` integrity="%s" crossorigin="anonymous"`, hash))
+}
+
+// missingSRIHashes returns the assets in sriAssets that have no recorded
+// hash, and so are loaded from the CDN without integrity attributes.
+func missingSRIHashes() []string {
+	var missing []string
+	for _, asset := range sriAssets {
+		if _, ok := sriHashes[asset]; !ok {
+			missing = append(missing, asset)
+		}
+	}
+	return missing
+}
+
+// sriPath returns where refreshSRI writes sriFile: next to sri.go, where
+// the embed directive reads it, when the generator runs from its source
+// tree, otherwise the working directory.
+func sriPath() string {
+	if _, source, _, ok := runtime.Caller(0); ok {
+		path := filepath.Join(filepath.Dir(source), sriFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return sriFile
+}
+
+// refreshSRI downloads every asset in sriAssets from the CDN, records their
+// hashes in sriFile and uses them for this build. The generator has to be
+// rebuilt for later builds to pick the file up.
+func refreshSRI() error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	hashes := make(map[string]string, len(sriAssets))
+	for _, asset := range sriAssets {
+		hash, err := fetchSRIHash(client, cdnBase+asset)
+		if err != nil {
+			return fmt.Errorf("hashing %s: %w", asset, err)
+		}
+		if old := sriHashes[asset]; old != hash {
+			fmt.Printf("🔒 %s: %s\n", asset, hash)
+		}
+		hashes[asset] = hash
+	}
+
+	content, err := json.MarshalIndent(hashes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", sriFile, err)
+	}
+	path := sriPath()
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	sriHashes = hashes
+	fmt.Printf("✓ Recorded %d integrity hashes in %s\n", len(hashes), path)
+	return nil
+}
+
+// fetchSRIHash returns the SHA-384 integrity hash of the file at url.
+func fetchSRIHash(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+	h := sha512.New384()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", err
+	}
+	return "sha384-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+)
+
+// schemaContext is the vocabulary the JSON-LD blocks describe pages with.
+const schemaContext = "https://schema.org"
+
+// learningResource describes an exercise page for search engines.
+type learningResource struct {
+	Context      string   `json:"@context"`
+	Type         []string `json:"@type"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Position     int      `json:"position"`
+	InLanguage   string   `json:"inLanguage"`
+	URL          string   `json:"url,omitempty"`
+	DateModified string   `json:"dateModified,omitempty"`
+	Keywords     []string `json:"keywords,omitempty"`
+}
+
+// itemList describes the index page as the ordered list of exercises.
+type itemList struct {
+	Context         string         `json:"@context"`
+	Type            string         `json:"@type"`
+	Name            string         `json:"name"`
+	NumberOfItems   int            `json:"numberOfItems"`
+	ItemListElement []itemListItem `json:"itemListElement"`
+}
+
+type itemListItem struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	URL      string `json:"url,omitempty"`
+}
+
+// exerciseStructuredData returns the JSON-LD for an exercise page. URLs
+// are only included under -base-url, as they have to be absolute.
+func exerciseStructuredData(ex Exercise) (template.JS, error) {
+	resource := learningResource{
+		Context:     schemaContext,
+		Type:        []string{"LearningResource", "TechArticle"},
+		Name:        ex.Title,
+		Description: ex.Description,
+		Position:    ex.Index + 1,
+		InLanguage:  ex.Lang,
+		URL:         ex.AbsoluteURL,
+		Keywords:    ex.Tags,
+	}
+	if !ex.LastUpdated.IsZero() {
+		resource.DateModified = ex.LastUpdated.UTC().Format("2006-01-02")
+	}
+	return marshalStructuredData(resource)
+}
+
+// indexStructuredData returns the JSON-LD for an index page listing
+// exercises under the given name.
+func indexStructuredData(name string, exercises []Exercise) (template.JS, error) {
+	list := itemList{
+		Context:         schemaContext,
+		Type:            "ItemList",
+		Name:            name,
+		NumberOfItems:   len(exercises),
+		ItemListElement: make([]itemListItem, len(exercises)),
+	}
+	for i, ex := range exercises {
+		list.ItemListElement[i] = itemListItem{
+			Type:     "ListItem",
+			Position: i + 1,
+			Name:     ex.Title,
+			URL:      ex.AbsoluteURL,
+		}
+	}
+	return marshalStructuredData(list)
+}
+
+// marshalStructuredData encodes v for a <script type="application/ld+json">
+// block. encoding/json escapes <, > and &, so the text can't close the
+// script element.
+func marshalStructuredData(v any) (template.JS, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encoding structured data: %w", err)
+	}
+	return template.JS(content), nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// tagLink is a tag chip linking to the tag's page.
+type tagLink struct {
+	Name string
+	// URL is relative to the page the chip is on
+	URL string
+}
+
+// tagPageFiles returns the file name of each tag's page, e.g.
+// "tag-runtime.html", relative to the language directory. Tags are slugged
+// like headings; when two slug alike, later ones in sorted order get a
+// numbered file, e.g. "tag-runtime-2.html".
+func tagPageFiles(tags []string) map[string]string {
+	files := make(map[string]string, len(tags))
+	used := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		base := "tag-" + slugify(tag)
+		file := base + ".html"
+		for n := 2; used[file]; n++ {
+			file = base + "-" + strconv.Itoa(n) + ".html"
+		}
+		used[file] = true
+		files[tag] = file
+	}
+	return files
+}
+
+// linkTags sets the tag chips of every exercise, linking to the tag pages
+// of the language the exercises belong to.
+func linkTags(exercises []Exercise) {
+	files := tagPageFiles(exerciseTags(exercises))
+	for i, ex := range exercises {
+		links := make([]tagLink, len(ex.Tags))
+		for j, tag := range ex.Tags {
+			links[j] = tagLink{Name: tag, URL: ex.HomePath + files[tag]}
+		}
+		exercises[i].TagLinks = links
+	}
+}
+
+// generateTagPages writes a page for every tag of a language, next to its
+// index page. Tag pages always use .html file names, whatever the
+// -trailing-slash policy, since they sit in the language directory itself.
+func generateTagPages(outputDir string, lang LangConfig, exercises []Exercise, siteRoot string, opts buildOptions) error {
+	tags := exerciseTags(exercises)
+	files := tagPageFiles(tags)
+	for _, tag := range tags {
+		if err := generateTagPage(outputDir, lang, tag, files, exercises, siteRoot, opts); err != nil {
+			return fmt.Errorf("generating tag page %q (%s): %w", tag, lang.Code, err)
+		}
+	}
+	if len(tags) > 0 {
+		fmt.Printf("✓ Generated %d tag pages [%s]\n", len(tags), lang.Code)
+	}
+	return nil
+}
+
+// generateTagPage writes the page of a tag, listing the exercises that
+// carry it with the index page's cards, and chips leading to the pages of
+// the other tags.
+func generateTagPage(outputDir string, lang LangConfig, tag string, files map[string]string, exercises []Exercise, siteRoot string, opts buildOptions) error {
+	var tagged []Exercise
+	for _, ex := range exercises {
+		if slices.Contains(ex.Tags, tag) {
+			tagged = append(tagged, ex)
+		}
+	}
+	tags := exerciseTags(exercises)
+	links := make([]tagLink, len(tags))
+	for i, other := range tags {
+		links[i] = tagLink{Name: other, URL: files[other]}
+	}
+
+	tmpl, _, err := parseTemplate("tag", tagPageTemplate, "")
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Lang      string
+		UI        UIStrings
+		Tag       string
+		Title     string
+		CSSPath   string
+		Favicon   template.URL
+		AssetBase string
+		HomeURL   string
+		Tags      []tagLink
+		Exercises []Exercise
+	}{
+		Lang:      lang.Code,
+		UI:        lang.UIStrings,
+		Tag:       tag,
+		Title:     fmt.Sprintf(lang.UIStrings.TaggedTitle, tag),
+		CSSPath:   siteRoot + "style.css",
+		Favicon:   faviconURL(siteRoot, opts),
+		AssetBase: assetBase(opts.Offline, siteRoot),
+		HomeURL:   opts.URLs.pageURL("index"),
+		Tags:      links,
+		Exercises: tagged,
+	}
+	return writePage(filepath.Join(outputDir, files[tag]), pageAbsoluteURL(opts.BaseURL, lang, files[tag]), tmpl, data, opts)
+}
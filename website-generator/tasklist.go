@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// taskMarkerRe matches the GitHub-style "[ ]" or "[x]" opening a task list
+// item.
+var taskMarkerRe = regexp.MustCompile(`^\[([ xX])\][ \t]+`)
+
+// parseTaskItem reports whether the list item node opens with a task
+// marker and, if so, replaces the marker with a disabled checkbox at the
+// start of the item's first paragraph, checked for "[x]".
+func parseTaskItem(node *blackfriday.Node) bool {
+	if node.ListFlags&(blackfriday.ListTypeDefinition|blackfriday.ListTypeTerm) != 0 || node.ListData.RefLink != nil {
+		return false
+	}
+	para := node.FirstChild
+	if para == nil || para.Type != blackfriday.Paragraph {
+		return false
+	}
+	text := para.FirstChild
+	if text == nil || text.Type != blackfriday.Text {
+		return false
+	}
+	m := taskMarkerRe.FindSubmatch(text.Literal)
+	if m == nil {
+		return false
+	}
+
+	checkbox := blackfriday.NewNode(blackfriday.HTMLSpan)
+	checkbox.Literal = []byte(`<input type="checkbox" class="task-list-checkbox" disabled="disabled"> `)
+	if m[1][0] != ' ' {
+		checkbox.Literal = []byte(`<input type="checkbox" class="task-list-checkbox" disabled="disabled" checked="checked"> `)
+	}
+	text.Literal = text.Literal[len(m[0]):]
+	text.InsertBefore(checkbox)
+	return true
+}
+
+// writeTaskItemStart opens the task list item node as blackfriday would
+// open any item, with the task-list-item class.
+func (r markdownRenderer) writeTaskItemStart(w io.Writer, node *blackfriday.Node) {
+	var buf bytes.Buffer
+	r.HTMLRenderer.RenderNode(&buf, node, true)
+	w.Write(bytes.Replace(buf.Bytes(), []byte("<li>"), []byte(`<li class="task-list-item">`), 1))
+}
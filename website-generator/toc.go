@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	headingRe   = regexp.MustCompile(`(?s)<h([2-6])((?:\s[^>]*)?)>(.*?)</h[2-6]>`)
+	headingIDRe = regexp.MustCompile(`\bid="([^"]*)"`)
+)
+
+// tocHeading is a heading collected for the table of contents.
+type tocHeading struct {
+	level int
+	id    string
+	text  string
+}
+
+// maxAnchorLevel is the deepest heading that gets a visible "#" link.
+const maxAnchorLevel = 4
+
+// anchorHeadings gives every <h2> to <h6> heading in the rendered HTML an id
+// attribute, keeping ids that are already set, and appends a "#" link to
+// headings down to <h4> so readers can link to a section. It returns the
+// updated HTML and the headings in page order.
+func anchorHeadings(rendered string) (string, []tocHeading) {
+	var headings []tocHeading
+	ids := make(headingIDs)
+	rendered = headingRe.ReplaceAllStringFunc(rendered, func(match string) string {
+		m := headingRe.FindStringSubmatch(match)
+		level, _ := strconv.Atoi(m[1])
+		text := strings.Join(strings.Fields(html.UnescapeString(htmlTagRe.ReplaceAllString(m[3], ""))), " ")
+
+		if id := headingIDRe.FindStringSubmatch(m[2]); id != nil {
+			ids[id[1]] = true
+			headings = append(headings, tocHeading{level, id[1], text})
+			return match
+		}
+
+		id := ids.unique(text)
+		headings = append(headings, tocHeading{level, id, text})
+
+		anchor := ""
+		if level <= maxAnchorLevel {
+			anchor = fmt.Sprintf(` <a class="heading-anchor" href="#%s" aria-label="Link to this section">#</a>`, id)
+		}
+		return fmt.Sprintf(`<h%s id="%s"%s>%s%s</h%s>`, m[1], id, m[2], m[3], anchor, m[1])
+	})
+	return rendered, headings
+}
+
+// buildTOC returns a nested table of contents of the headings down to
+// <h{depth}>. Pages with fewer than two such headings get none.
+func buildTOC(headings []tocHeading, depth int) template.HTML {
+	var listed []tocHeading
+	for _, h := range headings {
+		if h.level <= depth {
+			listed = append(listed, h)
+		}
+	}
+	if len(listed) < 2 {
+		return ""
+	}
+	return template.HTML(renderTOC(listed))
+}
+
+// headingIDs is the set of heading ids taken on a page.
+type headingIDs map[string]bool
+
+// unique returns the id of a heading with the given text and takes it: its
+// slug, with "-1", "-2", ... appended for the second, third, ... heading
+// that slugs alike, as GitHub numbers them. Called in document order, a
+// page's ids are the same on every build.
+func (ids headingIDs) unique(text string) string {
+	base := slugify(text)
+	id := base
+	for n := 1; ids[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	ids[id] = true
+	return id
+}
+
+// renderTOC nests the headings into lists by level.
+func renderTOC(headings []tocHeading) string {
+	var b strings.Builder
+	var stack []int
+	for _, h := range headings {
+		switch {
+		case len(stack) == 0 || h.level > stack[len(stack)-1]:
+			b.WriteString("<ul>")
+			stack = append(stack, h.level)
+		default:
+			b.WriteString("</li>")
+			for len(stack) > 1 && h.level < stack[len(stack)-1] {
+				b.WriteString("</ul></li>")
+				stack = stack[:len(stack)-1]
+			}
+		}
+		fmt.Fprintf(&b, `<li><a href="#%s">%s</a>`, h.id, html.EscapeString(h.text))
+	}
+	b.WriteString("</li>")
+	for i := len(stack); i > 1; i-- {
+		b.WriteString("</ul></li>")
+	}
+	b.WriteString("</ul>")
+	return b.String()
+}
+
+// slugify turns heading text into an id, e.g. "Step 1: Navigate to the
+// Scanner" becomes "step-1-navigate-to-the-scanner": runs of spaces and
+// punctuation become a single hyphen, trimmed at the ends. Letters outside
+// ASCII are kept so Spanish headings stay readable; browsers percent-encode
+// them in URLs. Heading ids, tag page names and the templates' slugify
+// all come from it.
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Transform stages: rules run either on the markdown source before
+// rendering or on the rendered HTML.
+const (
+	stageMarkdown = "markdown"
+	stageHTML     = "html"
+)
+
+// defaultMaxSubstitutions caps how many replacements a single rule may make
+// on one page, so a pattern that is broader than intended fails loudly
+// instead of silently rewriting the whole workshop.
+const defaultMaxSubstitutions = 1000
+
+// transformRule is a single find/replace rule from the transforms file.
+type transformRule struct {
+	Name        string `yaml:"name"`
+	Pattern     string `yaml:"pattern"`
+	Replacement string `yaml:"replacement"`
+	Stage       string `yaml:"stage"`
+	Max         int    `yaml:"max"`
+
+	re *regexp.Regexp
+	// count is updated by pages rendered in parallel
+	count atomic.Int64
+}
+
+// transformSet is the ordered list of rules loaded from a transforms file.
+type transformSet struct {
+	Rules []*transformRule `yaml:"rules"`
+}
+
+// loadTransforms reads and validates a YAML transforms file.
+func loadTransforms(path string) (*transformSet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading transforms file: %w", err)
+	}
+
+	var set transformSet
+	if err := yaml.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("parsing transforms file %s: %w", path, err)
+	}
+
+	for i, rule := range set.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if rule.Stage == "" {
+			rule.Stage = stageHTML
+		}
+		if rule.Stage != stageMarkdown && rule.Stage != stageHTML {
+			return nil, fmt.Errorf("%s: %s: stage must be %q or %q, got %q", path, rule.Name, stageMarkdown, stageHTML, rule.Stage)
+		}
+		if rule.Max <= 0 {
+			rule.Max = defaultMaxSubstitutions
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: invalid pattern: %w", path, rule.Name, err)
+		}
+		// A pattern matching the empty string would insert the
+		// replacement between every character
+		if re.MatchString("") {
+			return nil, fmt.Errorf("%s: %s: pattern %q matches the empty string", path, rule.Name, rule.Pattern)
+		}
+		rule.re = re
+	}
+
+	return &set, nil
+}
+
+// apply runs every rule for the given stage over content, in file order.
+// A nil set leaves content untouched.
+func (t *transformSet) apply(stage, page string, content []byte) ([]byte, error) {
+	if t == nil {
+		return content, nil
+	}
+
+	for _, rule := range t.Rules {
+		if rule.Stage != stage {
+			continue
+		}
+		n := len(rule.re.FindAllIndex(content, rule.Max+1))
+		if n > rule.Max {
+			return nil, fmt.Errorf("transform %s: more than %d substitutions in %s", rule.Name, rule.Max, page)
+		}
+		if n == 0 {
+			continue
+		}
+		content = rule.re.ReplaceAll(content, []byte(rule.Replacement))
+		rule.count.Add(int64(n))
+	}
+	return content, nil
+}
+
+// reset clears the substitution counters before a new build.
+func (t *transformSet) reset() {
+	if t == nil {
+		return
+	}
+	for _, rule := range t.Rules {
+		rule.count.Store(0)
+	}
+}
+
+// report prints how many substitutions each rule made during the build.
+func (t *transformSet) report() {
+	if t == nil {
+		return
+	}
+	for _, rule := range t.Rules {
+		fmt.Printf("🔁 Transform %s (%s): %d substitutions\n", rule.Name, rule.Stage, rule.count.Load())
+	}
+}
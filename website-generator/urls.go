@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Trailing-slash policies. The default keeps classic NN-name.html URLs;
+// the other two produce clean URLs without the .html extension.
+const (
+	trailingSlashDefault = ""
+	// trailingSlashAlways writes NN-name/index.html, linked as NN-name/
+	trailingSlashAlways = "always"
+	// trailingSlashNever writes NN-name.html, linked as NN-name (the host
+	// is expected to resolve extensionless URLs)
+	trailingSlashNever = "never"
+)
+
+// URL styles of -url-style, a shorthand for the trailing-slash policies
+// that only change the file layout: flat NN-name.html pages, or pretty
+// NN-name/index.html ones served at NN-name/.
+const (
+	urlStyleFlat   = "flat"
+	urlStylePretty = "pretty"
+)
+
+// urlStylePolicy returns the trailing-slash policy of a -url-style.
+func urlStylePolicy(style string) (string, error) {
+	switch style {
+	case urlStyleFlat:
+		return trailingSlashDefault, nil
+	case urlStylePretty:
+		return trailingSlashAlways, nil
+	}
+	return "", fmt.Errorf("invalid -url-style %q (want flat or pretty)", style)
+}
+
+// urlPolicy decides where pages are written and how internal links to them
+// are spelled. Every internal link goes through it so navigation, content
+// links and the index always agree.
+type urlPolicy struct {
+	TrailingSlash string
+}
+
+func (p urlPolicy) validate() error {
+	switch p.TrailingSlash {
+	case trailingSlashDefault, trailingSlashAlways, trailingSlashNever:
+		return nil
+	}
+	return fmt.Errorf("invalid -trailing-slash %q (want always or never)", p.TrailingSlash)
+}
+
+// clean reports whether pages use extensionless URLs.
+func (p urlPolicy) clean() bool {
+	return p.TrailingSlash != trailingSlashDefault
+}
+
+// pageFile returns the output path of a page, relative to its language
+// directory. name is the page name without extension, e.g.
+// "02-scanner-arrow-operator" or "index".
+func (p urlPolicy) pageFile(name string) string {
+	if p.TrailingSlash == trailingSlashAlways && name != "index" {
+		return path.Join(name, "index.html")
+	}
+	return name + ".html"
+}
+
+// pageURL returns the URL of a page relative to its language directory.
+func (p urlPolicy) pageURL(name string) string {
+	if name == "index" {
+		if p.clean() {
+			return "./"
+		}
+		return "index.html"
+	}
+	switch p.TrailingSlash {
+	case trailingSlashAlways:
+		return name + "/"
+	case trailingSlashNever:
+		return name
+	}
+	return name + ".html"
+}
+
+// rootPrefix returns the relative path from a page back to its language
+// directory, e.g. "" or "../", with one more "../" per subdirectory.
+func (p urlPolicy) rootPrefix(name string) string {
+	depth := strings.Count(name, "/")
+	if p.TrailingSlash == trailingSlashAlways && name != "index" {
+		depth++
+	}
+	return strings.Repeat("../", depth)
+}
+
+// link returns the relative URL from page from to page to, both named
+// relative to the same language directory.
+func (p urlPolicy) link(from, to string) string {
+	prefix := p.rootPrefix(from)
+	target := p.pageURL(to)
+	if target == "./" && prefix != "" {
+		return prefix
+	}
+	return prefix + target
+}
+
+// linkAttrRe matches the href and src attributes of a rendered page.
+var linkAttrRe = regexp.MustCompile(`(\s(?:href|src)=")([^"]*)"`)
+
+// absoluteLinks resolves the relative href and src attributes of a page
+// against pageURL, the page's address under -base-url, so internal links
+// carry the deployment path. URLs with a scheme, root-relative paths and
+// in-page anchors are left alone, and so are inline scripts, whose markup
+// strings are built at run time. Without a pageURL the page is unchanged.
+func absoluteLinks(page []byte, pageURL string) []byte {
+	base, err := url.Parse(pageURL)
+	if pageURL == "" || err != nil {
+		return page
+	}
+	var out []byte
+	last := 0
+	for _, loc := range scriptRe.FindAllIndex(page, -1) {
+		out = append(out, absoluteAttrs(page[last:loc[0]], base)...)
+		out = append(out, page[loc[0]:loc[1]]...)
+		last = loc[1]
+	}
+	return append(out, absoluteAttrs(page[last:], base)...)
+}
+
+// absoluteAttrs resolves the relative href and src attributes of markup
+// against base.
+func absoluteAttrs(markup []byte, base *url.URL) []byte {
+	return linkAttrRe.ReplaceAllFunc(markup, func(match []byte) []byte {
+		m := linkAttrRe.FindSubmatch(match)
+		ref := html.UnescapeString(string(m[2]))
+		if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "/") {
+			return match
+		}
+		u, err := url.Parse(ref)
+		if err != nil || u.IsAbs() {
+			return match
+		}
+		return []byte(string(m[1]) + html.EscapeString(base.ResolveReference(u).String()) + `"`)
+	})
+}
+
+var renderedPageLinkRe = regexp.MustCompile(`href="((?:[^":#?/]+/)*)(index|[0-9]{2}-[^"#?/]+)\.html([#?][^"]*)?"`)
+
+// resolvePageLink returns the name, relative to the language directory, of
+// the page a rendered link points to. dir is the link's directory part and
+// from the page containing it. index.html always means the language's
+// index page. It returns "" for links leaving the language directory.
+func resolvePageLink(from, dir, name string) string {
+	if name == "index" {
+		return "index"
+	}
+	target := path.Join(path.Dir(from), dir, name)
+	if strings.HasPrefix(target, "../") {
+		return ""
+	}
+	return target
+}
+
+// rewriteLinks rewrites the NN-name.html and index.html links produced by
+// markdownToHTML so they follow the policy, relative to page from.
+func (p urlPolicy) rewriteLinks(html, from string) string {
+	if !p.clean() && p.rootPrefix(from) == "" {
+		return html
+	}
+	return renderedPageLinkRe.ReplaceAllStringFunc(html, func(match string) string {
+		m := renderedPageLinkRe.FindStringSubmatch(match)
+		target := resolvePageLink(from, m[1], m[2])
+		if target == "" {
+			return match
+		}
+		return fmt.Sprintf(`href="%s%s"`, p.link(from, target), m[3])
+	})
+}
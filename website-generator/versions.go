@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionDirRe matches a directory of the -versions layout, named after the
+// Go release its exercises target, e.g. "v1.25".
+var versionDirRe = regexp.MustCompile(`^v[0-9]+(?:\.[0-9]+)*$`)
+
+// versionLink is an entry of the version switcher: the same page built for
+// another Go release, or that release's index when it lacks the exercise.
+type versionLink struct {
+	// Name is the release without the "v", e.g. "1.25"
+	Name    string
+	URL     string
+	Current bool
+}
+
+// discoverVersions returns the version directories in exercisesDir, newest
+// first.
+func discoverVersions(exercisesDir string) ([]string, error) {
+	entries, err := os.ReadDir(exercisesDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading exercises directory: %w", err)
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() && versionDirRe.MatchString(entry.Name()) {
+			versions = append(versions, entry.Name())
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("-versions: no version directories such as v1.25 in %s", exercisesDir)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+	return versions, nil
+}
+
+// compareVersions orders two version directory names numerically, so
+// v1.10 is newer than v1.9.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// buildVersions builds every version directory of exercisesDir as a site
+// of its own in the same directory of outputDir, targeting the Go release
+// it is named after, and an index.html at the output root that redirects
+// to the newest. It returns the number of pages written.
+func buildVersions(exercisesDir, outputDir string, opts buildOptions) (int, error) {
+	versions, err := discoverVersions(exercisesDir)
+	if err != nil {
+		return 0, err
+	}
+	opts.Versions = versions
+	opts.versionsDir = exercisesDir
+
+	totalPages := 0
+	for _, version := range versions {
+		fmt.Printf("📦 Building %s\n", version)
+		versionOpts := opts
+		versionOpts.Version = version
+		versionOpts.GoVersion = strings.TrimPrefix(version, "v")
+		if opts.BaseURL != "" {
+			versionOpts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/") + "/" + version + "/"
+		}
+		pages, err := buildSite(filepath.Join(exercisesDir, version), filepath.Join(outputDir, version), versionOpts)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", version, err)
+		}
+		totalPages += pages
+	}
+
+	if opts.DryRun {
+		return totalPages, nil
+	}
+	if err := generateVersionRedirect(outputDir, versions[0], opts.URLs); err != nil {
+		return 0, err
+	}
+	return totalPages, nil
+}
+
+// versionLinks returns the version switcher of a page in lang, or nil
+// outside the -versions layout. name is the page's exercise, empty for the
+// index, and root the relative path from the page to its version's output
+// directory.
+func versionLinks(root string, lang LangConfig, name string, opts buildOptions) []versionLink {
+	if opts.Version == "" {
+		return nil
+	}
+	langDir := ""
+	if lang.OutputPrefix != "" {
+		langDir = lang.OutputPrefix + "/"
+	}
+	base, _ := languageByCode(lang.Code)
+
+	links := make([]versionLink, len(opts.Versions))
+	for i, version := range opts.Versions {
+		target := "index"
+		if name != "" {
+			dir := filepath.Join(opts.versionsDir, version)
+			if _, err := os.Stat(filepath.Join(dir, withSourceDir(dir, base).sourceFile(name))); err == nil {
+				target = name
+			}
+		}
+		page := opts.URLs.pageURL(target)
+		if page == "./" {
+			page = ""
+		}
+		links[i] = versionLink{
+			Name:    strings.TrimPrefix(version, "v"),
+			URL:     root + "../" + version + "/" + langDir + page,
+			Current: version == opts.Version,
+		}
+	}
+	return links
+}
+
+// generateVersionRedirect writes the index.html at the output root that
+// sends readers on to the newest version.
+func generateVersionRedirect(outputDir, latest string, urls urlPolicy) error {
+	target := latest + "/"
+	if page := urls.pageURL("index"); page != "./" {
+		target += page
+	}
+	target = html.EscapeString(target)
+	content := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Having fun with the Go Source Code</title>
+    <link rel="canonical" href="%s">
+    <meta http-equiv="refresh" content="0; url=%s">
+</head>
+<body>
+    <p><a href="%s">Go %s</a></p>
+</body>
+</html>
+`, target, target, target, html.EscapeString(strings.TrimPrefix(latest, "v")))
+	if err := os.WriteFile(filepath.Join(outputDir, "index.html"), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("writing version redirect: %w", err)
+	}
+
+	fmt.Printf("✓ Generated index.html redirecting to %s\n", latest)
+	return nil
+}
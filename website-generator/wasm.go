@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// wasmDirectiveRe matches a {{wasm "path/to/program.go"}} directive on a
+// line of its own.
+var wasmDirectiveRe = regexp.MustCompile(`(?m)^\{\{wasm "([^"]+)"\}\}[ \t]*\r?$`)
+
+// wasmBuilder compiles the Go programs referenced by wasm directives to
+// WebAssembly and places them, along with the wasm_exec.js glue, in the
+// output directory. Each program is compiled once per build.
+type wasmBuilder struct {
+	exercisesDir string
+	outputDir    string
+	goBin        string
+
+	// mu serializes builds from pages rendered in parallel
+	mu         sync.Mutex
+	built      map[string]string
+	copiedGlue bool
+}
+
+// newWasmBuilder returns a builder, or nil if no Go toolchain is available,
+// in which case examples are rendered as plain source code.
+func newWasmBuilder(exercisesDir, outputDir string, diags *diagnostics) *wasmBuilder {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		diags.warnf(categoryBuild, "", "Go toolchain not found, WASM examples will be shown as source only")
+		return nil
+	}
+	return &wasmBuilder{
+		exercisesDir: exercisesDir,
+		outputDir:    outputDir,
+		goBin:        goBin,
+		built:        make(map[string]string),
+	}
+}
+
+// expandWasmDirectives replaces every wasm directive in the markdown with
+// an HTML block showing the program source and, when the builder is
+// available, a button that runs it in the browser. siteRoot is the relative
+// path from the page to the output root. It reports whether any runnable
+// example was embedded.
+func expandWasmDirectives(markdown []byte, exercisesDir, siteRoot string, builder *wasmBuilder) ([]byte, bool, error) {
+	hasWasm := false
+	var firstErr error
+	out := wasmDirectiveRe.ReplaceAllFunc(markdown, func(match []byte) []byte {
+		if firstErr != nil {
+			return match
+		}
+		src := string(wasmDirectiveRe.FindSubmatch(match)[1])
+		source, err := os.ReadFile(filepath.Join(exercisesDir, src))
+		if err != nil {
+			firstErr = fmt.Errorf("wasm example: %w", err)
+			return match
+		}
+
+		var b strings.Builder
+		b.WriteString("\n<div class=\"wasm-example\">\n")
+		b.WriteString(codeBlock("go", string(source)))
+		if builder != nil {
+			wasmPath, err := builder.build(src)
+			if err != nil {
+				firstErr = err
+				return match
+			}
+			hasWasm = true
+			fmt.Fprintf(&b, "<button type=\"button\" class=\"wasm-run\" data-wasm=\"%s\">▶ Run</button>\n", html.EscapeString(siteRoot+wasmPath))
+			b.WriteString("<div class=\"wasm-output\" hidden></div>\n")
+		}
+		b.WriteString("</div>\n")
+		return []byte(b.String())
+	})
+	return out, hasWasm, firstErr
+}
+
+// build compiles the program at src (relative to the exercises directory)
+// and returns the .wasm path relative to the output root.
+func (w *wasmBuilder) build(src string) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if wasmPath, ok := w.built[src]; ok {
+		return wasmPath, nil
+	}
+
+	name := strings.TrimSuffix(filepath.ToSlash(src), ".go")
+	wasmPath := "wasm/" + strings.ReplaceAll(name, "/", "-") + ".wasm"
+	outputPath := filepath.Join(w.outputDir, filepath.FromSlash(wasmPath))
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return "", fmt.Errorf("creating wasm directory: %w", err)
+	}
+
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command(w.goBin, "build", "-o", absOutput, filepath.Base(src))
+	cmd.Dir = filepath.Join(w.exercisesDir, filepath.Dir(src))
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("compiling wasm example %s: %w: %s", src, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := w.copyGlue(); err != nil {
+		return "", err
+	}
+
+	w.built[src] = wasmPath
+	fmt.Printf("✓ Compiled %s\n", wasmPath)
+	return wasmPath, nil
+}
+
+// copyGlue copies the toolchain's wasm_exec.js next to the compiled
+// examples. Its location moved from misc/wasm to lib/wasm in Go 1.24.
+func (w *wasmBuilder) copyGlue() error {
+	if w.copiedGlue {
+		return nil
+	}
+
+	goroot, err := exec.Command(w.goBin, "env", "GOROOT").Output()
+	if err != nil {
+		return fmt.Errorf("locating GOROOT: %w", err)
+	}
+	root := strings.TrimSpace(string(goroot))
+
+	var glue []byte
+	for _, dir := range []string{"lib/wasm", "misc/wasm"} {
+		if glue, err = os.ReadFile(filepath.Join(root, dir, "wasm_exec.js")); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("reading wasm_exec.js: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(w.outputDir, "wasm_exec.js"), glue, 0o644); err != nil {
+		return fmt.Errorf("writing wasm_exec.js: %w", err)
+	}
+	w.copiedGlue = true
+	return nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the watcher waits after the last change before
+// rebuilding, so an editor's burst of writes triggers a single rebuild.
+const watchDebounce = 200 * time.Millisecond
+
+// siteState remembers what the last full build produced so watch mode can
+// regenerate a single exercise page and its index.
+type siteState struct {
+	opts  buildOptions
+	langs []builtLanguage
+}
+
+// builtLanguage is one language of the last full build.
+type builtLanguage struct {
+	lang             LangConfig
+	outputDir        string
+	siteRoot         string
+	altLangURLPrefix string
+	exercises        []Exercise
+}
+
+// siteWatcher rebuilds the site when exercises or template files change.
+type siteWatcher struct {
+	exercisesDir string
+	outputDir    string
+	opts         buildOptions
+	state        *siteState
+	watcher      *fsnotify.Watcher
+	// onRebuild, if set, is called after every successful rebuild
+	onRebuild func()
+}
+
+// watchSite builds the site, then watches the exercises directory and any
+// external template files and rebuilds on every change until the watcher
+// fails.
+func watchSite(exercisesDir, outputDir string, opts buildOptions, onRebuild func()) error {
+	w := &siteWatcher{
+		exercisesDir: exercisesDir,
+		outputDir:    outputDir,
+		state:        &siteState{},
+		onRebuild:    onRebuild,
+	}
+	opts.state = w.state
+	w.opts = opts
+
+	if err := w.fullRebuild(); err != nil {
+		return fmt.Errorf("initial build failed: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("starting file watcher: %w", err)
+	}
+	defer watcher.Close()
+	w.watcher = watcher
+
+	if err := w.addTree(exercisesDir); err != nil {
+		return err
+	}
+	// Editors often replace a file rather than write to it, so template
+	// files are watched through their directories
+	for _, file := range opts.Templates.files() {
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			return fmt.Errorf("watching %s: %w", file, err)
+		}
+	}
+
+	fmt.Printf("👀 Watching %s for changes...\n", exercisesDir)
+	return w.loop()
+}
+
+// addTree watches dir and every directory below it.
+func (w *siteWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if err := w.watcher.Add(path); err != nil {
+				return fmt.Errorf("watching %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (w *siteWatcher) loop() error {
+	pending := make(map[string]bool)
+	var timer <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.addTree(event.Name); err != nil {
+						fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+					}
+				}
+			}
+			if !w.relevant(event.Name) {
+				continue
+			}
+			pending[event.Name] = true
+			timer = time.After(watchDebounce)
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  Watcher error: %v\n", err)
+
+		case <-timer:
+			timer = nil
+			changed := make([]string, 0, len(pending))
+			for path := range pending {
+				changed = append(changed, path)
+			}
+			sort.Strings(changed)
+			pending = make(map[string]bool)
+
+			if err := w.rebuild(changed); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Rebuild error: %v\n", err)
+				continue
+			}
+			if w.onRebuild != nil {
+				w.onRebuild()
+			}
+		}
+	}
+}
+
+// relevant reports whether a change to path affects the site: a markdown
+// file in the exercises directory or one of the template files.
+func (w *siteWatcher) relevant(path string) bool {
+	if w.isTemplateFile(path) {
+		return true
+	}
+	return isMarkdownFile(path) && strings.HasPrefix(path, filepath.Clean(w.exercisesDir)+string(filepath.Separator))
+}
+
+func (w *siteWatcher) isTemplateFile(path string) bool {
+	for _, file := range w.opts.Templates.files() {
+		if filepath.Clean(file) == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuild regenerates the site after the given files changed. Edits to
+// existing exercises only regenerate their pages and the index; anything
+// else (new, removed or renamed files, templates) rebuilds everything.
+func (w *siteWatcher) rebuild(changed []string) error {
+	names := make([]string, len(changed))
+	for i, path := range changed {
+		names[i], _ = filepath.Rel(w.exercisesDir, path)
+		if w.isTemplateFile(path) {
+			names[i] = path
+		}
+	}
+	fmt.Printf("🔄 Changed: %s\n", strings.Join(names, ", "))
+
+	edits := make(map[int][]int)
+	for _, path := range changed {
+		li, ei, ok := w.findExercise(path)
+		if !ok {
+			return w.fullRebuild()
+		}
+		edits[li] = append(edits[li], ei)
+	}
+
+	w.opts.diags.reset()
+	for li := range w.state.langs {
+		indexes, ok := edits[li]
+		if !ok {
+			continue
+		}
+		ok, err := w.rebuildExercises(&w.state.langs[li], indexes)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return w.fullRebuild()
+		}
+	}
+	// The sitemap dates every exercise of every language
+	if opts := w.state.opts; opts.BaseURL != "" {
+		var all []Exercise
+		for _, built := range w.state.langs {
+			all = append(all, built.exercises...)
+		}
+		if err := generateSitemap(w.outputDir, opts.BaseURL, all, opts.URLs); err != nil {
+			return fmt.Errorf("generating sitemap: %w", err)
+		}
+	}
+	if err := w.state.opts.cache.save(); err != nil {
+		return err
+	}
+	w.opts.diags.printSummary(true)
+	fmt.Println("✅ Rebuild complete")
+	return nil
+}
+
+// findExercise locates the exercise whose markdown file is path in the last
+// build. It fails for files that were added or removed since.
+func (w *siteWatcher) findExercise(path string) (int, int, bool) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, 0, false
+	}
+	rel, err := filepath.Rel(w.exercisesDir, path)
+	if err != nil {
+		return 0, 0, false
+	}
+	for li, built := range w.state.langs {
+		for ei, meta := range built.lang.Metadata {
+			if built.lang.sourceFile(meta.Filename) == rel {
+				return li, ei, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// rebuildExercises reloads the exercises at the given indexes and writes
+// their pages, the language's index page and its other files, such as the
+// search index, tag pages and workshop map. It reports false, without
+// writing anything, when a title, emoji, description or tags changed: those
+// show up in every page's sidebar, related exercises or tag chips, so the
+// whole site has to be rebuilt. JSON output is always rebuilt in full.
+func (w *siteWatcher) rebuildExercises(built *builtLanguage, indexes []int) (bool, error) {
+	opts := w.state.opts
+	if opts.OutputFormat == outputFormatJSON {
+		return false, nil
+	}
+	reloaded := make(map[int]Exercise, len(indexes))
+	for _, i := range indexes {
+		meta := built.lang.Metadata[i]
+		exercise, err := loadExercise(w.exercisesDir, built.lang, meta, i, built.siteRoot, built.altLangURLPrefix, opts)
+		if err != nil {
+			return false, fmt.Errorf("generating exercise %s (%s): %w", meta.Filename, built.lang.Code, err)
+		}
+		if old := built.exercises[i]; exercise.Title != old.Title || exercise.Emoji != old.Emoji || exercise.Description != old.Description || !slices.Equal(exercise.Tags, old.Tags) {
+			return false, nil
+		}
+		reloaded[i] = exercise
+	}
+	for i, exercise := range reloaded {
+		built.exercises[i] = exercise
+	}
+	checkPrerequisites(built.lang, built.exercises, opts.diags)
+	linkTags(built.exercises)
+	for _, i := range indexes {
+		if err := generateExercisePage(built.outputDir, ExercisePageData{Exercise: built.exercises[i], All: built.exercises}, opts); err != nil {
+			return false, fmt.Errorf("generating exercise %s (%s): %w", built.exercises[i].Name, built.lang.Code, err)
+		}
+	}
+	if err := generateIndexPage(built.outputDir, built.lang, built.exercises, built.siteRoot, built.altLangURLPrefix, opts); err != nil {
+		return false, fmt.Errorf("generating index page (%s): %w", built.lang.Code, err)
+	}
+	// The search index, map, feed and the other per-language files are
+	// built from every exercise's content, so they are written again too
+	if err := generateLanguageFiles(built.outputDir, built.lang, built.exercises, built.siteRoot, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (w *siteWatcher) fullRebuild() error {
+	if files := w.opts.Templates.files(); len(files) > 0 {
+		t := w.opts.Templates
+		templates, err := loadTemplates(t.exerciseFile, t.indexFile, t.cssFile)
+		if err != nil {
+			return err
+		}
+		w.opts.Templates = templates
+	}
+
+	fmt.Println("🔄 Rebuilding website...")
+	w.opts.diags.reset()
+	if _, err := buildSite(w.exercisesDir, w.outputDir, w.opts); err != nil {
+		return err
+	}
+	w.opts.diags.printSummary(true)
+	fmt.Println("✅ Rebuild complete")
+	return nil
+}